@@ -0,0 +1,134 @@
+package dynamotest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/rozen03/dynamotest/avcmp"
+)
+
+// The assertions below take keys and expected items either as Go values,
+// marshalled with attributevalue like initial data, or as items in their
+// DynamoDB form. Items are compared attribute by attribute with avcmp, so
+// numbers are compared by value and sets regardless of their order, and
+// mismatches are reported as a diff.
+
+// AssertItem fails the test unless the item with the given key exists in the
+// table and equals want.
+func AssertItem(t *testing.T, c Client, table string, key, want any) {
+	t.Helper()
+
+	got := getItem(t, c, table, key)
+	if got == nil {
+		t.Errorf("item %s not found in table '%s'", formatItem(t, toItemOrFatal(t, key)), table)
+		return
+	}
+
+	wantItem := toItemOrFatal(t, want)
+	if diff := avcmp.Diff(wantItem, got); diff != "" {
+		t.Errorf("item %s in table '%s' didn't match (-want / +got)\n%s", formatItem(t, toItemOrFatal(t, key)), table, diff)
+	}
+}
+
+// AssertNoItem fails the test if an item with the given key exists in the
+// table.
+func AssertNoItem(t *testing.T, c Client, table string, key any) {
+	t.Helper()
+
+	if got := getItem(t, c, table, key); got != nil {
+		t.Errorf("expected no item with key %s in table '%s', got %s", formatItem(t, toItemOrFatal(t, key)), table, formatItem(t, got))
+	}
+}
+
+// AssertItemCount fails the test unless the table holds want items.
+func AssertItemCount(t *testing.T, c Client, table string, want int) {
+	t.Helper()
+
+	got := 0
+	paginator := dynamodb.NewScanPaginator(c.Client, &dynamodb.ScanInput{
+		TableName:      aws.String(table),
+		Select:         types.SelectCount,
+		ConsistentRead: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(internal(context.Background()))
+		if err != nil {
+			t.Fatalf("could not count items of table '%s': %v", table, err)
+		}
+		got += int(out.Count)
+	}
+
+	if got != want {
+		t.Errorf("expected %d items in table '%s', got %d", want, table, got)
+	}
+}
+
+// AssertTableEquals fails the test unless the table holds exactly wantItems,
+// in any order.
+func AssertTableEquals(t *testing.T, c Client, table string, wantItems ...any) {
+	t.Helper()
+
+	got, err := c.DumpTableE(context.Background(), table)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	keySchema, err := c.keySchema(context.Background(), table)
+	if err != nil {
+		t.Fatalf("could not describe table '%s': %v", table, err)
+	}
+
+	want := make([]map[string]types.AttributeValue, len(wantItems))
+	for i, item := range wantItems {
+		want[i] = toItemOrFatal(t, item)
+	}
+	sortItems(keySchema, want)
+
+	if diff := avcmp.Diff(want, got); diff != "" {
+		t.Errorf("table '%s' didn't match (-want / +got)\n%s", table, diff)
+	}
+}
+
+// getItem returns the item with the given key, or nil when there is none.
+func getItem(t *testing.T, c Client, table string, key any) map[string]types.AttributeValue {
+	t.Helper()
+
+	out, err := c.Client.GetItem(internal(context.Background()), &dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            toItemOrFatal(t, key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatalf("could not get item from table '%s': %v", table, err)
+	}
+	return out.Item
+}
+
+func toItemOrFatal(t *testing.T, v any) map[string]types.AttributeValue {
+	t.Helper()
+
+	item, err := toItem(v)
+	if err != nil {
+		t.Fatalf("could not marshal %v: %v", v, err)
+	}
+	return item
+}
+
+// formatItem formats an item as compact DynamoDB JSON for messages.
+func formatItem(t *testing.T, item map[string]types.AttributeValue) string {
+	t.Helper()
+
+	v, err := typedJSON(&types.AttributeValueMemberM{Value: item})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	data, err := json.Marshal(v.(map[string]any)["M"])
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return string(data)
+}
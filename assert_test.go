@@ -0,0 +1,64 @@
+package dynamotest_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/rozen03/dynamotest"
+)
+
+type assertedItem struct {
+	ID    string            `dynamodbav:"id"`
+	Tags  []string          `dynamodbav:"tags,stringset"`
+	Attrs map[string]string `dynamodbav:"attrs"`
+	Data  []byte            `dynamodbav:"data"`
+	Note  *string           `dynamodbav:"note,nullempty"`
+}
+
+func TestAssertions(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	first := assertedItem{
+		ID:    "1",
+		Tags:  []string{"b", "a"},
+		Attrs: map[string]string{"color": "red"},
+		Data:  []byte{1, 2},
+	}
+	second := map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: "2"},
+		"scores": &types.AttributeValueMemberNS{Value: []string{"2", "1.5"}},
+		"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberBOOL{Value: true},
+			&types.AttributeValueMemberNULL{Value: true},
+		}},
+	}
+
+	table := client.CreateTestingTable(t, "assert", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	}, second, first)
+
+	// Sets are compared regardless of their order.
+	first.Tags = []string{"a", "b"}
+
+	dynamotest.AssertItem(t, client, table, map[string]string{"id": "1"}, first)
+	dynamotest.AssertItem(t, client, table, map[string]string{"id": "2"}, second)
+	dynamotest.AssertNoItem(t, client, table, map[string]string{"id": "3"})
+	dynamotest.AssertItemCount(t, client, table, 2)
+	dynamotest.AssertTableEquals(t, client, table, second, first)
+}
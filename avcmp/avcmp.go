@@ -0,0 +1,139 @@
+// Package avcmp compares DynamoDB attribute values by what they hold rather
+// than by how they are written: numbers are compared by value, so "1.0"
+// equals "1", sets are compared regardless of their order, and maps and lists
+// are compared element by element.
+package avcmp
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Equal reports whether a and b hold the same value.
+func Equal(a, b types.AttributeValue) bool {
+	return cmp.Equal(a, b, Transform())
+}
+
+// Diff returns a human readable diff of want and got, or an empty string when
+// they are equal. They may be attribute values, items, lists of items or any
+// value holding attribute values, such as a struct.
+func Diff(want, got any) string {
+	return cmp.Diff(want, got, Transform())
+}
+
+// Transform returns a cmp.Option comparing attribute values wherever they
+// appear, through Normalize. Diffs point at the mismatched attributes.
+func Transform() cmp.Option {
+	return cmp.Transformer("avcmp.Normalize", Normalize)
+}
+
+// Comparer returns a cmp.Option comparing attribute values with Equal. Unlike
+// Transform, a mismatch reports the whole attribute values, which keeps the
+// diffs of large values short.
+func Comparer() cmp.Option {
+	return cmp.Comparer(Equal)
+}
+
+// Normalize converts an attribute value into its DynamoDB JSON form, e.g.
+// map[string]any{"N": "1.5"}, with canonical numbers and sorted, deduplicated
+// sets, so that equal values are normalized identically.
+func Normalize(av types.AttributeValue) any {
+	switch v := av.(type) {
+	case nil:
+		return nil
+	case *types.AttributeValueMemberS:
+		return map[string]any{"S": v.Value}
+	case *types.AttributeValueMemberN:
+		return map[string]any{"N": CanonicalNumber(v.Value)}
+	case *types.AttributeValueMemberB:
+		return map[string]any{"B": v.Value}
+	case *types.AttributeValueMemberBOOL:
+		return map[string]any{"BOOL": v.Value}
+	case *types.AttributeValueMemberNULL:
+		return map[string]any{"NULL": v.Value}
+	case *types.AttributeValueMemberSS:
+		return map[string]any{"SS": uniqueSorted(v.Value, strings.Compare)}
+	case *types.AttributeValueMemberNS:
+		numbers := make([]string, len(v.Value))
+		for i, n := range v.Value {
+			numbers[i] = CanonicalNumber(n)
+		}
+		return map[string]any{"NS": uniqueSorted(numbers, compareNumbers)}
+	case *types.AttributeValueMemberBS:
+		return map[string]any{"BS": uniqueSorted(v.Value, bytes.Compare)}
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for i, e := range v.Value {
+			list[i] = Normalize(e)
+		}
+		return map[string]any{"L": list}
+	case *types.AttributeValueMemberM:
+		m := make(map[string]any, len(v.Value))
+		for k, e := range v.Value {
+			m[k] = Normalize(e)
+		}
+		return map[string]any{"M": m}
+	}
+	return map[string]any{fmt.Sprintf("%T", av): fmt.Sprintf("%+v", av)}
+}
+
+// CanonicalNumber returns the shortest decimal form of a DynamoDB number,
+// without exponent, leading or trailing zeros, e.g. "1.50e2" becomes "150".
+// Strings that are not numbers are returned unchanged.
+func CanonicalNumber(n string) string {
+	r, ok := new(big.Rat).SetString(n)
+	if !ok {
+		return n
+	}
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// The denominator of a decimal is a product of powers of 2 and 5, so it
+	// is written exactly with as many decimals as the larger of the powers.
+	digits := 0
+	denom := new(big.Int).Set(r.Denom())
+	for _, factor := range []int64{2, 5} {
+		count := 0
+		f := big.NewInt(factor)
+		mod := new(big.Int)
+		for {
+			q, m := new(big.Int).QuoRem(denom, f, mod)
+			if m.Sign() != 0 {
+				break
+			}
+			denom = q
+			count++
+		}
+		digits = max(digits, count)
+	}
+	return strings.TrimRight(r.FloatString(digits), "0")
+}
+
+func compareNumbers(a, b string) int {
+	ra, okA := new(big.Rat).SetString(a)
+	rb, okB := new(big.Rat).SetString(b)
+	if !okA || !okB {
+		return strings.Compare(a, b)
+	}
+	return ra.Cmp(rb)
+}
+
+func uniqueSorted[T any](values []T, compare func(a, b T) int) []T {
+	out := append([]T{}, values...)
+	sort.SliceStable(out, func(i, j int) bool { return compare(out[i], out[j]) < 0 })
+
+	unique := out[:0]
+	for i, v := range out {
+		if i == 0 || compare(unique[len(unique)-1], v) != 0 {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
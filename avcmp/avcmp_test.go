@@ -0,0 +1,201 @@
+package avcmp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest/avcmp"
+)
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		a, b types.AttributeValue
+		want bool
+	}{
+		"equal strings": {
+			a:    &types.AttributeValueMemberS{Value: "a"},
+			b:    &types.AttributeValueMemberS{Value: "a"},
+			want: true,
+		},
+		"different strings": {
+			a: &types.AttributeValueMemberS{Value: "a"},
+			b: &types.AttributeValueMemberS{Value: "b"},
+		},
+		"numbers compared by value": {
+			a:    &types.AttributeValueMemberN{Value: "1.0"},
+			b:    &types.AttributeValueMemberN{Value: "1"},
+			want: true,
+		},
+		"numbers with exponent": {
+			a:    &types.AttributeValueMemberN{Value: "1.50e2"},
+			b:    &types.AttributeValueMemberN{Value: "150"},
+			want: true,
+		},
+		"negative fractions": {
+			a:    &types.AttributeValueMemberN{Value: "-0.250"},
+			b:    &types.AttributeValueMemberN{Value: "-.25"},
+			want: true,
+		},
+		"different numbers": {
+			a: &types.AttributeValueMemberN{Value: "1.01"},
+			b: &types.AttributeValueMemberN{Value: "1"},
+		},
+		"number and string": {
+			a: &types.AttributeValueMemberN{Value: "1"},
+			b: &types.AttributeValueMemberS{Value: "1"},
+		},
+		"binary": {
+			a:    &types.AttributeValueMemberB{Value: []byte("x")},
+			b:    &types.AttributeValueMemberB{Value: []byte("x")},
+			want: true,
+		},
+		"bool and null": {
+			a: &types.AttributeValueMemberBOOL{Value: true},
+			b: &types.AttributeValueMemberNULL{Value: true},
+		},
+		"string sets in any order": {
+			a:    &types.AttributeValueMemberSS{Value: []string{"b", "a"}},
+			b:    &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			want: true,
+		},
+		"different string sets": {
+			a: &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			b: &types.AttributeValueMemberSS{Value: []string{"a", "c"}},
+		},
+		"number sets in any order and form": {
+			a:    &types.AttributeValueMemberNS{Value: []string{"10", "1.50"}},
+			b:    &types.AttributeValueMemberNS{Value: []string{"1.5", "1e1"}},
+			want: true,
+		},
+		"binary sets in any order": {
+			a:    &types.AttributeValueMemberBS{Value: [][]byte{[]byte("z"), []byte("a")}},
+			b:    &types.AttributeValueMemberBS{Value: [][]byte{[]byte("a"), []byte("z")}},
+			want: true,
+		},
+		"nested maps and lists": {
+			a: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberN{Value: "2.0"},
+					&types.AttributeValueMemberSS{Value: []string{"y", "x"}},
+				}},
+			}},
+			b: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberN{Value: "2"},
+					&types.AttributeValueMemberSS{Value: []string{"x", "y"}},
+				}},
+			}},
+			want: true,
+		},
+		"lists keep their order": {
+			a: &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "a"},
+				&types.AttributeValueMemberS{Value: "b"},
+			}},
+			b: &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "b"},
+				&types.AttributeValueMemberS{Value: "a"},
+			}},
+		},
+		"missing map attribute": {
+			a: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"a": &types.AttributeValueMemberS{Value: "a"},
+			}},
+			b: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+		},
+		"nil": {
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := avcmp.Equal(tc.a, tc.b); got != tc.want {
+				t.Errorf("Equal(a, b) = %v, want %v", got, tc.want)
+			}
+			if got := avcmp.Equal(tc.b, tc.a); got != tc.want {
+				t.Errorf("Equal(b, a) = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalNumber(t *testing.T) {
+	t.Parallel()
+	cases := map[string]string{
+		"1":         "1",
+		"1.0":       "1",
+		"-0":        "0",
+		"007":       "7",
+		"0.50":      "0.5",
+		"-.25":      "-0.25",
+		"1.5e2":     "150",
+		"12E-3":     "0.012",
+		"1e-40":     "0.0000000000000000000000000000000000000001",
+		"not a num": "not a num",
+	}
+	for n, want := range cases {
+		n, want := n, want
+		t.Run(n, func(t *testing.T) {
+			t.Parallel()
+
+			if got := avcmp.CanonicalNumber(n); got != want {
+				t.Errorf("CanonicalNumber(%q) = %q, want %q", n, got, want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	want := []map[string]types.AttributeValue{{
+		"id":    &types.AttributeValueMemberS{Value: "1"},
+		"price": &types.AttributeValueMemberN{Value: "9.90"},
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"b", "a"}},
+	}}
+	got := []map[string]types.AttributeValue{{
+		"id":    &types.AttributeValueMemberS{Value: "1"},
+		"price": &types.AttributeValueMemberN{Value: "9.9"},
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+	}}
+	if diff := avcmp.Diff(want, got); diff != "" {
+		t.Errorf("expected no diff, got\n%s", diff)
+	}
+
+	got[0]["price"] = &types.AttributeValueMemberN{Value: "10"}
+	diff := avcmp.Diff(want, got)
+	if !strings.Contains(diff, `"price"`) || !strings.Contains(diff, `"9.9"`) || !strings.Contains(diff, `"10"`) {
+		t.Errorf("expected the diff to show the mismatched price, got\n%s", diff)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	t.Parallel()
+
+	type page struct {
+		Items []map[string]types.AttributeValue
+		Count int
+	}
+	want := page{Count: 1, Items: []map[string]types.AttributeValue{{
+		"n": &types.AttributeValueMemberN{Value: "1"},
+	}}}
+	got := page{Count: 1, Items: []map[string]types.AttributeValue{{
+		"n": &types.AttributeValueMemberN{Value: "1.00"},
+	}}}
+
+	for name, opt := range map[string]cmp.Option{
+		"Transform": avcmp.Transform(),
+		"Comparer":  avcmp.Comparer(),
+	} {
+		if diff := cmp.Diff(want, got, opt); diff != "" {
+			t.Errorf("%s: pages didn't match (-want / +got)\n%s", name, diff)
+		}
+	}
+}
@@ -0,0 +1,613 @@
+package dynamotest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/rozen03/dynamotest/avcmp"
+)
+
+// WithCapacityAccounting estimates the capacity consumed by the requests of the
+// Client, for the assertions of Client.Capacity.
+func WithCapacityAccounting() Option {
+	return func(o *options) { o.capacity = true }
+}
+
+// Consumption is the capacity a call is estimated to consume.
+type Consumption struct {
+	Operation string
+	Tables    []string
+	RCU       float64
+	WCU       float64
+}
+
+func (c Consumption) String() string {
+	return fmt.Sprintf("%s on %s: %g RCU, %g WCU", c.Operation, strings.Join(c.Tables, ", "), c.RCU, c.WCU)
+}
+
+// CapacityAccountant estimates the read and write capacity units the calls
+// made through a Client would consume on DynamoDB, which DynamoDB Local does
+// not track, so that tests can catch a cheap Query turning into a Scan.
+//
+// Estimates follow the DynamoDB pricing rules: reads cost a unit per 4KB,
+// half of it when eventually consistent, writes a unit per 1KB, transactions
+// twice as much, and writes to items with the keys of a global secondary index
+// also write its projection. Queries and scans are charged for the items
+// they scanned, of the average item size DescribeTable reports, so that
+// filters, projections and Select=COUNT do not hide their cost. Writes are
+// charged for the larger of the item before and after them, which are read
+// around the call. Failed calls and the harness' own requests are not counted.
+//
+// Reading the items costs extra requests, so accounting is off unless the
+// Client is created with WithCapacityAccounting. The items can also change
+// between those reads and the call when other tests write to them.
+//
+// Consumption is counted until Reset for every call made through the Client,
+// so tests sharing a Client should either reset it first and not run in
+// parallel, or only assert on the consumption of their own tables.
+type CapacityAccountant struct {
+	client  *dynamodb.Client
+	enabled bool
+
+	mu       sync.Mutex
+	consumed []Consumption
+	tables   map[string]keySchema
+	sizes    map[string]tableSizes
+}
+
+// indexKeys are the attributes a global secondary index writes for an item:
+// the keys of the table and of the index, and its projection.
+type indexKeys struct {
+	tableKeys  []string
+	keys       []string
+	projection types.ProjectionType
+	nonKey     []string
+}
+
+// Consumed returns the estimated consumption of every call since Reset, in
+// the order they were made. When tables are given, only the calls on any of
+// them are returned.
+func (a *CapacityAccountant) Consumed(tables ...string) []Consumption {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var consumed []Consumption
+	for _, c := range a.consumed {
+		if len(tables) == 0 || slices.ContainsFunc(c.Tables, func(table string) bool { return slices.Contains(tables, table) }) {
+			consumed = append(consumed, c)
+		}
+	}
+	return consumed
+}
+
+// RCU returns the estimated read capacity units consumed since Reset, by the
+// calls on any of the tables when given.
+func (a *CapacityAccountant) RCU(tables ...string) float64 {
+	total := 0.0
+	for _, c := range a.Consumed(tables...) {
+		total += c.RCU
+	}
+	return total
+}
+
+// WCU returns the estimated write capacity units consumed since Reset, by the
+// calls on any of the tables when given.
+func (a *CapacityAccountant) WCU(tables ...string) float64 {
+	total := 0.0
+	for _, c := range a.Consumed(tables...) {
+		total += c.WCU
+	}
+	return total
+}
+
+// AssertMaxRCU fails the test if more than limit read capacity units have been
+// consumed since Reset, by the calls on any of the tables when given.
+func (a *CapacityAccountant) AssertMaxRCU(t *testing.T, limit float64, tables ...string) {
+	t.Helper()
+
+	a.requireEnabled(t)
+	if got := a.RCU(tables...); got > limit {
+		t.Errorf("expected at most %g RCU, consumed %g:\n%s", limit, got, a.breakdown(tables, func(c Consumption) bool { return c.RCU > 0 }))
+	}
+}
+
+// AssertMaxWCU fails the test if more than limit write capacity units have been
+// consumed since Reset, by the calls on any of the tables when given.
+func (a *CapacityAccountant) AssertMaxWCU(t *testing.T, limit float64, tables ...string) {
+	t.Helper()
+
+	a.requireEnabled(t)
+	if got := a.WCU(tables...); got > limit {
+		t.Errorf("expected at most %g WCU, consumed %g:\n%s", limit, got, a.breakdown(tables, func(c Consumption) bool { return c.WCU > 0 }))
+	}
+}
+
+func (a *CapacityAccountant) requireEnabled(t *testing.T) {
+	t.Helper()
+
+	if !a.enabled {
+		t.Fatalf("capacity accounting is off, create the Client with dynamotest.WithCapacityAccounting()")
+	}
+}
+
+// Reset forgets the consumed capacity.
+func (a *CapacityAccountant) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.consumed = nil
+}
+
+func (a *CapacityAccountant) breakdown(tables []string, keep func(Consumption) bool) string {
+	var lines []string
+	for _, c := range a.Consumed(tables...) {
+		if keep(c) {
+			lines = append(lines, "\t"+c.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addMiddleware estimates the capacity of the calls before the retry
+// middleware, so that every call is counted once. The items a call writes are
+// read beforehand, as the indexes holding them are written as well.
+func (a *CapacityAccountant) addMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("dynamotest.Capacity",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			if isInternal(ctx) {
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				a.forgetSizes(in.Parameters)
+				return out, metadata, err
+			}
+			writes := a.itemWrites(ctx, in.Parameters)
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			a.forgetSizes(in.Parameters)
+			if err != nil {
+				return out, metadata, err
+			}
+
+			c := Consumption{
+				Operation: awsmiddleware.GetOperationName(ctx),
+				Tables:    requestTables(in.Parameters),
+			}
+			c.RCU, c.WCU = a.estimate(ctx, in.Parameters, out.Result, writes)
+
+			a.mu.Lock()
+			a.consumed = append(a.consumed, c)
+			a.mu.Unlock()
+			return out, metadata, err
+		}), middleware.After)
+}
+
+func (a *CapacityAccountant) estimate(ctx context.Context, input, output any, writes []itemWrite) (rcu, wcu float64) {
+	switch in := input.(type) {
+	case *dynamodb.GetItemInput:
+		out := output.(*dynamodb.GetItemOutput)
+		rcu = readUnits(itemSize(out.Item), aws.ToBool(in.ConsistentRead))
+	case *dynamodb.BatchGetItemInput:
+		out := output.(*dynamodb.BatchGetItemOutput)
+		for table, items := range out.Responses {
+			for _, item := range items {
+				rcu += readUnits(itemSize(item), aws.ToBool(in.RequestItems[table].ConsistentRead))
+			}
+		}
+	case *dynamodb.QueryInput:
+		out := output.(*dynamodb.QueryOutput)
+		size := a.pageSize(ctx, aws.ToString(in.TableName), aws.ToString(in.IndexName), out.Items, out.ScannedCount)
+		rcu = readUnits(size, aws.ToBool(in.ConsistentRead))
+	case *dynamodb.ScanInput:
+		out := output.(*dynamodb.ScanOutput)
+		size := a.pageSize(ctx, aws.ToString(in.TableName), aws.ToString(in.IndexName), out.Items, out.ScannedCount)
+		rcu = readUnits(size, aws.ToBool(in.ConsistentRead))
+	case *dynamodb.TransactGetItemsInput:
+		out := output.(*dynamodb.TransactGetItemsOutput)
+		for _, r := range out.Responses {
+			rcu += 2 * readUnits(itemSize(r.Item), true)
+		}
+	case *dynamodb.BatchWriteItemInput:
+		out := output.(*dynamodb.BatchWriteItemOutput)
+		for _, w := range writes {
+			if !w.unprocessed(out.UnprocessedItems) {
+				wcu += a.writeUnits(ctx, w)
+			}
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		for _, item := range in.TransactItems {
+			if item.ConditionCheck != nil {
+				wcu += 2 * writeUnits(itemSize(item.ConditionCheck.Key))
+			}
+		}
+		for _, w := range writes {
+			wcu += 2 * a.writeUnits(ctx, w)
+		}
+	default:
+		for _, w := range writes {
+			wcu += a.writeUnits(ctx, w)
+		}
+	}
+	return rcu, wcu
+}
+
+// itemWrite is an item changed by a write, with its attributes before and
+// after the call. Those after an update are only known once it is done.
+type itemWrite struct {
+	table  string
+	key    map[string]types.AttributeValue
+	update bool
+	before map[string]types.AttributeValue
+	after  map[string]types.AttributeValue
+}
+
+// itemWrites reads the items the write operation input changes.
+func (a *CapacityAccountant) itemWrites(ctx context.Context, input any) []itemWrite {
+	var writes []itemWrite
+	put := func(table string, item map[string]types.AttributeValue) {
+		writes = append(writes, itemWrite{table: table, key: a.itemKey(ctx, table, item), after: item})
+	}
+	update := func(table string, key map[string]types.AttributeValue) {
+		writes = append(writes, itemWrite{table: table, key: key, update: true})
+	}
+	remove := func(table string, key map[string]types.AttributeValue) {
+		writes = append(writes, itemWrite{table: table, key: key})
+	}
+
+	switch in := input.(type) {
+	case *dynamodb.PutItemInput:
+		put(aws.ToString(in.TableName), in.Item)
+	case *dynamodb.UpdateItemInput:
+		update(aws.ToString(in.TableName), in.Key)
+	case *dynamodb.DeleteItemInput:
+		remove(aws.ToString(in.TableName), in.Key)
+	case *dynamodb.BatchWriteItemInput:
+		for table, requests := range in.RequestItems {
+			for _, r := range requests {
+				switch {
+				case r.PutRequest != nil:
+					put(table, r.PutRequest.Item)
+				case r.DeleteRequest != nil:
+					remove(table, r.DeleteRequest.Key)
+				}
+			}
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		for _, item := range in.TransactItems {
+			switch {
+			case item.Put != nil:
+				put(aws.ToString(item.Put.TableName), item.Put.Item)
+			case item.Update != nil:
+				update(aws.ToString(item.Update.TableName), item.Update.Key)
+			case item.Delete != nil:
+				remove(aws.ToString(item.Delete.TableName), item.Delete.Key)
+			}
+		}
+	}
+
+	for i, w := range writes {
+		writes[i].before = a.getItem(ctx, w.table, w.key)
+	}
+	return writes
+}
+
+// unprocessed reports whether the write is among the unprocessed items of a
+// batch.
+func (w itemWrite) unprocessed(requests map[string][]types.WriteRequest) bool {
+	key := &types.AttributeValueMemberM{Value: w.key}
+	for _, r := range requests[w.table] {
+		switch {
+		case r.PutRequest != nil && w.after != nil:
+			if avcmp.Equal(&types.AttributeValueMemberM{Value: r.PutRequest.Item}, &types.AttributeValueMemberM{Value: w.after}) {
+				return true
+			}
+		case r.DeleteRequest != nil && w.after == nil:
+			if avcmp.Equal(&types.AttributeValueMemberM{Value: r.DeleteRequest.Key}, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeUnits estimates the units of a write: the larger of the item before
+// and after it, and the writes to the global secondary indexes holding either.
+func (a *CapacityAccountant) writeUnits(ctx context.Context, w itemWrite) float64 {
+	if w.update {
+		w.after = a.getItem(ctx, w.table, w.key)
+	}
+	wcu := writeUnits(max(itemSize(w.before), itemSize(w.after)))
+	for _, index := range a.describe(ctx, w.table).indexes {
+		wcu += index.writeUnits(w.before, w.after)
+	}
+	return wcu
+}
+
+// getItem reads an item on behalf of the harness, returning nil when it does
+// not exist or cannot be read.
+func (a *CapacityAccountant) getItem(ctx context.Context, table string, key map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if a.client == nil || len(key) == 0 {
+		return nil
+	}
+	out, err := a.client.GetItem(internal(ctx), &dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil
+	}
+	return out.Item
+}
+
+// itemKey returns the primary key of the item.
+func (a *CapacityAccountant) itemKey(ctx context.Context, table string, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{}
+	for _, name := range a.describe(ctx, table).keys {
+		if v, ok := item[name]; ok {
+			key[name] = v
+		}
+	}
+	return key
+}
+
+// project returns the attributes the index holds for the item, or nil when
+// the item does not have the keys of the index.
+func (index indexKeys) project(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil || !hasAttributes(item, index.keys) {
+		return nil
+	}
+	if index.projection == types.ProjectionTypeAll {
+		return item
+	}
+	projected := map[string]types.AttributeValue{}
+	names := append(append([]string{}, index.tableKeys...), index.keys...)
+	if index.projection == types.ProjectionTypeInclude {
+		names = append(names, index.nonKey...)
+	}
+	for _, name := range names {
+		if v, ok := item[name]; ok {
+			projected[name] = v
+		}
+	}
+	return projected
+}
+
+// writeUnits estimates the units written to the index when an item changes
+// from before to after: nothing if its projection is unchanged, an update if
+// its keys are, and otherwise a delete of the old projection and a put of the
+// new one.
+func (index indexKeys) writeUnits(before, after map[string]types.AttributeValue) float64 {
+	old, updated := index.project(before), index.project(after)
+	switch {
+	case old == nil && updated == nil:
+		return 0
+	case old == nil:
+		return writeUnits(itemSize(updated))
+	case updated == nil:
+		return writeUnits(itemSize(old))
+	case avcmp.Equal(&types.AttributeValueMemberM{Value: old}, &types.AttributeValueMemberM{Value: updated}):
+		return 0
+	}
+	for _, key := range index.keys {
+		if !avcmp.Equal(old[key], updated[key]) {
+			return writeUnits(itemSize(old)) + writeUnits(itemSize(updated))
+		}
+	}
+	return writeUnits(max(itemSize(old), itemSize(updated)))
+}
+
+// keySchema is the key attributes of a table and of its global secondary
+// indexes.
+type keySchema struct {
+	keys    []string
+	indexes []indexKeys
+}
+
+// describe describes the keys of the table once, none being assumed when the
+// table cannot be described.
+func (a *CapacityAccountant) describe(ctx context.Context, table string) keySchema {
+	a.mu.Lock()
+	keys, ok := a.tables[table]
+	a.mu.Unlock()
+	if ok || a.client == nil {
+		return keys
+	}
+
+	out, err := a.client.DescribeTable(internal(ctx), &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return keySchema{}
+	}
+	for _, k := range out.Table.KeySchema {
+		keys.keys = append(keys.keys, aws.ToString(k.AttributeName))
+	}
+	for _, gsi := range out.Table.GlobalSecondaryIndexes {
+		index := indexKeys{tableKeys: keys.keys, projection: types.ProjectionTypeAll}
+		for _, k := range gsi.KeySchema {
+			index.keys = append(index.keys, aws.ToString(k.AttributeName))
+		}
+		if gsi.Projection != nil {
+			index.projection = gsi.Projection.ProjectionType
+			index.nonKey = gsi.Projection.NonKeyAttributes
+		}
+		keys.indexes = append(keys.indexes, index)
+	}
+
+	a.mu.Lock()
+	if a.tables == nil {
+		a.tables = map[string]keySchema{}
+	}
+	a.tables[table] = keys
+	a.mu.Unlock()
+	return keys
+}
+
+func hasAttributes(item map[string]types.AttributeValue, names []string) bool {
+	for _, name := range names {
+		if _, ok := item[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// readUnits returns the units of reading size bytes, at least one.
+func readUnits(size int, consistent bool) float64 {
+	units := float64(max(1, (size+readUnitSize-1)/readUnitSize))
+	if !consistent {
+		return units / 2
+	}
+	return units
+}
+
+// writeUnits returns the units of writing size bytes, at least one.
+func writeUnits(size int) float64 {
+	return float64(max(1, (size+writeUnitSize-1)/writeUnitSize))
+}
+
+// pageSize returns the size of the items a page of a query or scan read,
+// which includes the items a filter dropped and the attributes a projection
+// left out: the scanned items, of the average size of the items of the table
+// or index. The returned items are scaled up instead when the table cannot
+// tell its size.
+func (a *CapacityAccountant) pageSize(ctx context.Context, table, index string, items []map[string]types.AttributeValue, scanned int32) int {
+	size := 0
+	for _, item := range items {
+		size += itemSize(item)
+	}
+	if average := a.averageItemSize(ctx, table, index); average > 0 {
+		return max(size, int(scanned)*average)
+	}
+	if len(items) > 0 && int(scanned) > len(items) {
+		size = size * int(scanned) / len(items)
+	}
+	return size
+}
+
+// tableSizes are the average sizes of the items of a table and of its
+// indexes, by index name, the table being under the empty name.
+type tableSizes map[string]int
+
+// averageItemSize returns the average size of the items of the table, or of
+// its index when given, or zero when it is unknown. Sizes are described once
+// until the table is written to.
+func (a *CapacityAccountant) averageItemSize(ctx context.Context, table, index string) int {
+	a.mu.Lock()
+	sizes, ok := a.sizes[table]
+	a.mu.Unlock()
+	if ok || a.client == nil {
+		return sizes[index]
+	}
+
+	out, err := a.client.DescribeTable(internal(ctx), &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return 0
+	}
+	sizes = tableSizes{}
+	average := func(name string, bytes, count *int64) {
+		if bytes != nil && count != nil && *count > 0 {
+			sizes[name] = int(*bytes / *count)
+		}
+	}
+	average("", out.Table.TableSizeBytes, out.Table.ItemCount)
+	for _, gsi := range out.Table.GlobalSecondaryIndexes {
+		average(aws.ToString(gsi.IndexName), gsi.IndexSizeBytes, gsi.ItemCount)
+	}
+	for _, lsi := range out.Table.LocalSecondaryIndexes {
+		average(aws.ToString(lsi.IndexName), lsi.IndexSizeBytes, lsi.ItemCount)
+	}
+
+	a.mu.Lock()
+	if a.sizes == nil {
+		a.sizes = map[string]tableSizes{}
+	}
+	a.sizes[table] = sizes
+	a.mu.Unlock()
+	return sizes[index]
+}
+
+// forgetSizes drops the sizes of the tables the input writes to, including
+// the writes of the harness, which seeds and resets tables.
+func (a *CapacityAccountant) forgetSizes(input any) {
+	switch input.(type) {
+	case *dynamodb.PutItemInput, *dynamodb.UpdateItemInput, *dynamodb.DeleteItemInput,
+		*dynamodb.BatchWriteItemInput, *dynamodb.TransactWriteItemsInput, *dynamodb.DeleteTableInput:
+	default:
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, table := range requestTables(input) {
+		delete(a.sizes, table)
+	}
+}
+
+// itemSize returns the size of an item as DynamoDB counts it, the lengths of
+// the attribute names plus the sizes of their values.
+func itemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, v := range item {
+		size += len(name) + valueSize(v)
+	}
+	return size
+}
+
+func valueSize(av types.AttributeValue) int {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += 1 + valueSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, e := range v.Value {
+			size += 1 + len(name) + valueSize(e)
+		}
+		return size
+	}
+	return 0
+}
+
+// numberSize returns the size of a number, a byte per two significant digits
+// plus one.
+func numberSize(n string) int {
+	mantissa, _, _ := strings.Cut(strings.ToLower(n), "e")
+	digits := strings.Trim(strings.NewReplacer("-", "", "+", "", ".", "").Replace(mantissa), "0")
+	return (len(digits)+1)/2 + 1
+}
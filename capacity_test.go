@@ -0,0 +1,157 @@
+package dynamotest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestCapacityAccountant(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t, dynamotest.WithCapacityAccounting())
+
+	table := client.CreateTestingTable(t, "capacity", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("status"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String("byStatus"),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("status"),
+						KeyType:       types.KeyTypeHash,
+					},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+			},
+		},
+	}, map[string]string{"id": "seeded", "status": "new"})
+	ctx := context.Background()
+
+	put := func(item map[string]string) {
+		av := map[string]types.AttributeValue{}
+		for k, v := range item {
+			av[k] = &types.AttributeValueMemberS{Value: v}
+		}
+		if _, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(table), Item: av}); err != nil {
+			t.Fatalf("failed to put item: %v", err)
+		}
+	}
+	get := func(consistent bool) {
+		_, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(table),
+			Key:            map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "2"}},
+			ConsistentRead: aws.Bool(consistent),
+		})
+		if err != nil {
+			t.Fatalf("failed to get item: %v", err)
+		}
+	}
+
+	// 12 bytes, written to the index as well.
+	put(map[string]string{"id": "1", "status": "new"})
+	// 5007 bytes, which the index does not hold.
+	put(map[string]string{"id": "2", "data": strings.Repeat("x", 5000)})
+	get(false)
+	get(true)
+	_, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String(table), ConsistentRead: aws.Bool(true)})
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	_, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to delete item: %v", err)
+	}
+
+	want := []dynamotest.Consumption{
+		{Operation: "PutItem", Tables: []string{table}, WCU: 2},
+		{Operation: "PutItem", Tables: []string{table}, WCU: 5},
+		{Operation: "GetItem", Tables: []string{table}, RCU: 1},
+		{Operation: "GetItem", Tables: []string{table}, RCU: 2},
+		// The seeded item and the two put ones, 5036 bytes.
+		{Operation: "Scan", Tables: []string{table}, RCU: 2},
+		{Operation: "DeleteItem", Tables: []string{table}, WCU: 2},
+	}
+	if diff := cmp.Diff(want, client.Capacity.Consumed()); diff != "" {
+		t.Errorf("consumption didn't match (-want / +got)\n%s", diff)
+	}
+	client.Capacity.AssertMaxRCU(t, 5)
+	client.Capacity.AssertMaxWCU(t, 9)
+
+	// Budgets can be limited to the tables of the test.
+	other := client.CreateTestingTable(t, "capacity", faultSchema)
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(other),
+		Item:      map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to put item: %v", err)
+	}
+	client.Capacity.AssertMaxWCU(t, 9, table)
+	if wcu := client.Capacity.WCU(other); wcu != 1 {
+		t.Errorf("expected 1 WCU on '%s', got %g", other, wcu)
+	}
+
+	// Filters and counts read every scanned item all the same.
+	client.Capacity.Reset()
+	_, err = client.Scan(ctx, &dynamodb.ScanInput{
+		TableName:        aws.String(table),
+		ConsistentRead:   aws.Bool(true),
+		Select:           types.SelectCount,
+		FilterExpression: aws.String("attribute_exists(missing)"),
+	})
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	if rcu := client.Capacity.RCU(); rcu < 2 {
+		t.Errorf("expected filtered count to read the 5 KB table, got %g RCU", rcu)
+	}
+
+	client.Capacity.Reset()
+	if rcu, wcu := client.Capacity.RCU(), client.Capacity.WCU(); rcu != 0 || wcu != 0 {
+		t.Errorf("expected no consumption after Reset, got %g RCU and %g WCU", rcu, wcu)
+	}
+}
+
+func TestCapacityAccountant_OffByDefault(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+	table := client.CreateTestingTable(t, "capacity", faultSchema)
+
+	_, err := client.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to put item: %v", err)
+	}
+	if got := client.Capacity.Consumed(); len(got) != 0 {
+		t.Errorf("expected no consumption without WithCapacityAccounting, got %v", got)
+	}
+}
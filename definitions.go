@@ -1,5 +1,7 @@
 package dynamotest
 
+import "time"
+
 var (
 	// DynamoDBLocalRepo is the repository for the DynamoDB Local image
 	// this is at least in 2004/06/23 https://hub.docker.com/r/amazon/dynamodb-local
@@ -9,4 +11,83 @@ var (
 	// this is set to latest just due to the fact that dynamoDB is a managed service
 	// and we want tests that are as close to the real thing as possible
 	dynamoDBLocalTag = "latest"
+
+	// readyTimeout is how long to wait for DynamoDB to answer requests, probing it every readyInterval
+	readyTimeout  = time.Minute
+	readyInterval = 250 * time.Millisecond
+
+	// containerLogsTail is how many lines of the container logs are shown when it does not become ready
+	containerLogsTail = "50"
+
+	// tableActiveTimeout is how long CreateTestingTable waits for a table and its indexes to
+	// become ACTIVE, unless Client.TableActiveTimeout is set
+	tableActiveTimeout = time.Minute
+
+	// tableActivePollInterval is how often the table status is checked while waiting
+	tableActivePollInterval = 250 * time.Millisecond
+
+	// tableDeleteTimeout is how long the test cleanup waits for a table to be deleted
+	tableDeleteTimeout = 30 * time.Second
+
+	// batchWriteLimit is the maximum number of items BatchWriteItem accepts in a single call
+	batchWriteLimit = 25
+
+	// batchWriteAttempts is how many times a chunk of items is sent before giving up on its
+	// unprocessed items, waiting batchWriteBackoff in between and doubling it up to batchWriteMaxBackoff
+	batchWriteAttempts   = 8
+	batchWriteBackoff    = 50 * time.Millisecond
+	batchWriteMaxBackoff = 2 * time.Second
+
+	// reuseLeaseTTL is how long a lease on a shared container is honoured without being
+	// refreshed every reuseLeaseRefresh, after which the process holding it is assumed to
+	// have died without releasing it
+	reuseLeaseTTL     = 5 * time.Minute
+	reuseLeaseRefresh = time.Minute
+
+	// reuseLockFile is the file in the lease directory locked while attaching to or purging a
+	// shared container. Where it cannot be locked by the OS, it is created exclusively and
+	// left by a process that died holding it for reuseLockTTL, polling every reuseLockInterval
+	reuseLockFile     = ".lock"
+	reuseLockTTL      = 10 * time.Minute
+	reuseLockInterval = 50 * time.Millisecond
+
+	// reuseAttempts is how many times a shared container is looked up or started when other
+	// processes race to start it
+	reuseAttempts = 3
+)
+
+const (
+	// dynamoDBLocalPort is the port DynamoDB Local listens on inside the container
+	dynamoDBLocalPort = "8000/tcp"
+
+	// keepTablesEnv overrides Client.KeepTables, accepted values are
+	// always (or 1, true), never (or 0, false) and on-failure
+	keepTablesEnv = "DYNAMOTEST_KEEP_TABLES"
+
+	// endpointEnv points at an external endpoint to use instead of starting a container
+	endpointEnv = "DYNAMOTEST_ENDPOINT"
+
+	// requireDockerEnv makes New fail instead of skipping the test when Docker is unavailable
+	requireDockerEnv = "DYNAMOTEST_REQUIRE_DOCKER"
+
+	// reuseEnv enables WithReuse, with defaultReuseName when set to 1 or true, or with its value as name
+	reuseEnv         = "DYNAMOTEST_REUSE"
+	defaultReuseName = "dynamotest"
+
+	// reuseLabel labels shared containers with their reuse name, and reuseContainerPrefix
+	// prefixes their container name
+	reuseLabel           = "dynamotest.reuse"
+	reuseContainerPrefix = "dynamotest-"
+
+	// updateSnapshotsEnv makes MatchSnapshot rewrite golden files like the -dynamotest.update flag,
+	// which is handy when testing packages that do not all import dynamotest
+	updateSnapshotsEnv = "DYNAMOTEST_UPDATE"
+
+	// snapshotDir and snapshotExt locate the golden files of MatchSnapshot
+	snapshotDir = "testdata"
+	snapshotExt = ".dynamo.json"
+
+	// readUnitSize and writeUnitSize are how many bytes a read and a write capacity unit cover
+	readUnitSize  = 4 * 1024
+	writeUnitSize = 1024
 )
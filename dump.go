@@ -0,0 +1,248 @@
+package dynamotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DumpTable returns every item of the table sorted by primary key, so the
+// state a test leaves behind can be checked or written to a golden file with
+// WriteTypedJSON or WritePlainJSON.
+func (c Client) DumpTable(t *testing.T, table string) []map[string]types.AttributeValue {
+	t.Helper()
+
+	items, err := c.DumpTableE(context.Background(), table)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return items
+}
+
+// DumpTableE works like DumpTable, but returns an error instead of failing
+// the test. The error wraps ErrTableDump.
+func (c Client) DumpTableE(ctx context.Context, table string) ([]map[string]types.AttributeValue, error) {
+	keySchema, err := c.keySchema(ctx, table)
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %w", ErrTableDump, table, err)
+	}
+
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(c.Client, &dynamodb.ScanInput{
+		TableName:      aws.String(table),
+		ConsistentRead: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(internal(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': %w", ErrTableDump, table, err)
+		}
+		items = append(items, out.Items...)
+	}
+
+	sortItems(keySchema, items)
+	return items, nil
+}
+
+// sortItems sorts items by their primary key, comparing numbers by value.
+func sortItems(keySchema []types.KeySchemaElement, items []map[string]types.AttributeValue) {
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, key := range keySchema {
+			name := aws.ToString(key.AttributeName)
+			if c := compareKeys(items[i][name], items[j][name]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+func compareKeys(a, b types.AttributeValue) int {
+	switch a := a.(type) {
+	case *types.AttributeValueMemberS:
+		if b, ok := b.(*types.AttributeValueMemberS); ok {
+			return strings.Compare(a.Value, b.Value)
+		}
+	case *types.AttributeValueMemberN:
+		if b, ok := b.(*types.AttributeValueMemberN); ok {
+			return compareNumbers(a.Value, b.Value)
+		}
+	case *types.AttributeValueMemberB:
+		if b, ok := b.(*types.AttributeValueMemberB); ok {
+			return bytes.Compare(a.Value, b.Value)
+		}
+	}
+	// Only expected items may lack a key or have one of another type, which
+	// are ordered by type to stay deterministic.
+	return strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b))
+}
+
+// compareNumbers compares DynamoDB numbers by value, falling back to their
+// text when one is not a number.
+func compareNumbers(a, b string) int {
+	ra, okA := new(big.Rat).SetString(a)
+	rb, okB := new(big.Rat).SetString(b)
+	if !okA || !okB {
+		return strings.Compare(a, b)
+	}
+	return ra.Cmp(rb)
+}
+
+// WriteTypedJSON writes items as a DynamoDB JSON array, e.g.
+// [{"id": {"S": "1"}}], which LoadFixtures reads back. Attributes are ordered
+// by name and sets by value, so the output only changes with the data.
+func WriteTypedJSON(w io.Writer, items []map[string]types.AttributeValue) error {
+	out := make([]any, len(items))
+	for i, item := range items {
+		v, err := typedJSON(&types.AttributeValueMemberM{Value: item})
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+		out[i] = v.(map[string]any)["M"]
+	}
+	return writeJSON(w, out)
+}
+
+// WritePlainJSON writes items as a plain JSON array, e.g. [{"id": "1"}], in
+// the same deterministic order as WriteTypedJSON. Binary values are written
+// as base64 and sets as arrays, so this is meant for reading rather than for
+// fixtures, as types JSON cannot tell apart are lost.
+func WritePlainJSON(w io.Writer, items []map[string]types.AttributeValue) error {
+	out := make([]any, len(items))
+	for i, item := range items {
+		v, err := plainJSON(&types.AttributeValueMemberM{Value: item})
+		if err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+		out[i] = v
+	}
+	return writeJSON(w, out)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// typedJSON converts an attribute value into its DynamoDB JSON form, with
+// sorted sets. encoding/json already sorts map keys.
+func typedJSON(av types.AttributeValue) (any, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]any{"S": v.Value}, nil
+	case *types.AttributeValueMemberN:
+		return map[string]any{"N": v.Value}, nil
+	case *types.AttributeValueMemberB:
+		return map[string]any{"B": v.Value}, nil
+	case *types.AttributeValueMemberBOOL:
+		return map[string]any{"BOOL": v.Value}, nil
+	case *types.AttributeValueMemberNULL:
+		return map[string]any{"NULL": true}, nil
+	case *types.AttributeValueMemberSS:
+		return map[string]any{"SS": sortedStrings(v.Value)}, nil
+	case *types.AttributeValueMemberNS:
+		return map[string]any{"NS": sortedNumbers(v.Value)}, nil
+	case *types.AttributeValueMemberBS:
+		return map[string]any{"BS": sortedBinaries(v.Value)}, nil
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for i, e := range v.Value {
+			converted, err := typedJSON(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			list[i] = converted
+		}
+		return map[string]any{"L": list}, nil
+	case *types.AttributeValueMemberM:
+		m := make(map[string]any, len(v.Value))
+		for k, e := range v.Value {
+			converted, err := typedJSON(e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			m[k] = converted
+		}
+		return map[string]any{"M": m}, nil
+	}
+	return nil, fmt.Errorf("unsupported attribute value %T", av)
+}
+
+// plainJSON converts an attribute value into plain JSON, keeping numbers
+// exact.
+func plainJSON(av types.AttributeValue) (any, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value, nil
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value), nil
+	case *types.AttributeValueMemberB:
+		return v.Value, nil
+	case *types.AttributeValueMemberBOOL:
+		return v.Value, nil
+	case *types.AttributeValueMemberNULL:
+		return nil, nil
+	case *types.AttributeValueMemberSS:
+		return sortedStrings(v.Value), nil
+	case *types.AttributeValueMemberNS:
+		numbers := sortedNumbers(v.Value)
+		out := make([]json.Number, len(numbers))
+		for i, n := range numbers {
+			out[i] = json.Number(n)
+		}
+		return out, nil
+	case *types.AttributeValueMemberBS:
+		return sortedBinaries(v.Value), nil
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for i, e := range v.Value {
+			converted, err := plainJSON(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			list[i] = converted
+		}
+		return list, nil
+	case *types.AttributeValueMemberM:
+		m := make(map[string]any, len(v.Value))
+		for k, e := range v.Value {
+			converted, err := plainJSON(e)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			m[k] = converted
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported attribute value %T", av)
+}
+
+func sortedStrings(values []string) []string {
+	out := append([]string{}, values...)
+	sort.Strings(out)
+	return out
+}
+
+func sortedNumbers(values []string) []string {
+	out := append([]string{}, values...)
+	sort.SliceStable(out, func(i, j int) bool { return compareNumbers(out[i], out[j]) < 0 })
+	return out
+}
+
+func sortedBinaries(values [][]byte) [][]byte {
+	out := append([][]byte{}, values...)
+	sort.SliceStable(out, func(i, j int) bool { return bytes.Compare(out[i], out[j]) < 0 })
+	return out
+}
@@ -0,0 +1,203 @@
+package dynamotest_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest"
+	"github.com/rozen03/dynamotest/avcmp"
+)
+
+// dumpItems holds every attribute type, with sets out of order.
+var dumpItems = []map[string]types.AttributeValue{
+	{
+		"id":      &types.AttributeValueMemberS{Value: "1"},
+		"count":   &types.AttributeValueMemberN{Value: "10"},
+		"blob":    &types.AttributeValueMemberB{Value: []byte("hello")},
+		"active":  &types.AttributeValueMemberBOOL{Value: true},
+		"missing": &types.AttributeValueMemberNULL{Value: true},
+		"names":   &types.AttributeValueMemberSS{Value: []string{"b", "a"}},
+		"scores":  &types.AttributeValueMemberNS{Value: []string{"10", "9.5"}},
+		"blobs":   &types.AttributeValueMemberBS{Value: [][]byte{[]byte("z"), []byte("a")}},
+		"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "<x>"},
+		}},
+		"map": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"nested": &types.AttributeValueMemberN{Value: "1.5"},
+		}},
+	},
+}
+
+func TestWriteTypedJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := dynamotest.WriteTypedJSON(&buf, dumpItems); err != nil {
+		t.Fatalf("failed to write items: %v", err)
+	}
+
+	want := `[
+  {
+    "active": {
+      "BOOL": true
+    },
+    "blob": {
+      "B": "aGVsbG8="
+    },
+    "blobs": {
+      "BS": [
+        "YQ==",
+        "eg=="
+      ]
+    },
+    "count": {
+      "N": "10"
+    },
+    "id": {
+      "S": "1"
+    },
+    "list": {
+      "L": [
+        {
+          "S": "<x>"
+        }
+      ]
+    },
+    "map": {
+      "M": {
+        "nested": {
+          "N": "1.5"
+        }
+      }
+    },
+    "missing": {
+      "NULL": true
+    },
+    "names": {
+      "SS": [
+        "a",
+        "b"
+      ]
+    },
+    "scores": {
+      "NS": [
+        "9.5",
+        "10"
+      ]
+    }
+  }
+]
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("output didn't match (-want / +got)\n%s", diff)
+	}
+
+	// The output is a fixture LoadFixtures reads back.
+	path := filepath.Join(t.TempDir(), "dump.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write dump: %v", err)
+	}
+	items, err := dynamotest.LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("failed to load dump: %v", err)
+	}
+	var again bytes.Buffer
+	if err := dynamotest.WriteTypedJSON(&again, items); err != nil {
+		t.Fatalf("failed to write items: %v", err)
+	}
+	if diff := cmp.Diff(buf.String(), again.String()); diff != "" {
+		t.Errorf("loaded dump didn't match (-want / +got)\n%s", diff)
+	}
+}
+
+func TestWritePlainJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := dynamotest.WritePlainJSON(&buf, dumpItems); err != nil {
+		t.Fatalf("failed to write items: %v", err)
+	}
+
+	want := `[
+  {
+    "active": true,
+    "blob": "aGVsbG8=",
+    "blobs": [
+      "YQ==",
+      "eg=="
+    ],
+    "count": 10,
+    "id": "1",
+    "list": [
+      "<x>"
+    ],
+    "map": {
+      "nested": 1.5
+    },
+    "missing": null,
+    "names": [
+      "a",
+      "b"
+    ],
+    "scores": [
+      9.5,
+      10
+    ]
+  }
+]
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("output didn't match (-want / +got)\n%s", diff)
+	}
+}
+
+func TestDumpTable(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	table := client.CreateTestingTable(t, "dump", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("version"),
+				AttributeType: types.ScalarAttributeTypeN,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("version"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	},
+		map[string]any{"id": "b", "version": 1},
+		map[string]any{"id": "a", "version": 10},
+		map[string]any{"id": "a", "version": 9},
+	)
+
+	got := client.DumpTable(t, table)
+
+	want := []map[string]types.AttributeValue{
+		{"id": &types.AttributeValueMemberS{Value: "a"}, "version": &types.AttributeValueMemberN{Value: "9"}},
+		{"id": &types.AttributeValueMemberS{Value: "a"}, "version": &types.AttributeValueMemberN{Value: "10"}},
+		{"id": &types.AttributeValueMemberS{Value: "b"}, "version": &types.AttributeValueMemberN{Value: "1"}},
+	}
+	if diff := avcmp.Diff(want, got); diff != "" {
+		t.Errorf("items didn't match (-want / +got)\n%s", diff)
+	}
+}
@@ -1,9 +1,13 @@
 package dynamotest
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -32,70 +36,198 @@ func DynamoDBClient() Client {
 	return client
 }
 
+// New starts a DynamoDB Local instance dedicated to the test, or leases the
+// shared one when reuse is enabled, and purges it once the test and its
+// subtests complete. When Docker is unavailable the test is skipped, unless
+// WithDockerPolicy or the DYNAMOTEST_REQUIRE_DOCKER environment variable
+// asks for it to fail instead.
+func New(t *testing.T, opts ...Option) Client {
+	t.Helper()
+	o := newOptions(opts...)
+
+	c, purgeE, err := NewDynamoDBE(context.Background(), opts...)
+	if errors.Is(err, ErrDockerUnavailable) && o.dockerPolicyFor() == SkipWithoutDocker {
+		t.Skipf("Skipping test as Docker is unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("Could not start DynamoDB: %v", err)
+	}
+
+	if c.ContainerID != "" {
+		t.Logf("Using DynamoDB container %s at %s", c.ContainerID, c.Endpoint)
+	} else {
+		t.Logf("Using DynamoDB at %s", c.Endpoint)
+	}
+
+	t.Cleanup(func() {
+		if err := purgeE(); err != nil {
+			t.Errorf("Could not purge DynamoDB: %v", err)
+		}
+	})
+
+	return c
+}
+
 // NewDynamoDB creates a Docker container with DynamoDB Local, and returns the
 // connected DynamoDB client. Clean up function is returned as well to ensure
 // container gets removed after test is complete.
 func NewDynamoDB() (Client, func()) {
-	pool, err := dockertest.NewPool("")
+	return NewDynamoDBWithOptions()
+}
+
+// NewDynamoDBWithOptions works like NewDynamoDB, but lets the caller configure
+// the image, container and DynamoDB Local flags.
+func NewDynamoDBWithOptions(opts ...Option) (Client, func()) {
+	c, purgeE, err := NewDynamoDBE(context.Background(), opts...)
 	if err != nil {
-		panic("Could not connect to docker" + err.Error())
+		panic(err.Error())
 	}
 
-	runOpt := &dockertest.RunOptions{
-		Repository: dynamoDBLocalRepo,
-		Tag:        dynamoDBLocalTag,
+	client = c
+	purge = func() {
+		if err := purgeE(); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	return client, purge
+}
+
+// NewDynamoDBE works like NewDynamoDBWithOptions, but returns an error instead
+// of panicking. The error wraps one of ErrDockerUnavailable, ErrContainerStart
+// or ErrEndpointNotReady so callers can decide whether to skip, retry or fail.
+func NewDynamoDBE(ctx context.Context, opts ...Option) (Client, func() error, error) {
+	o := newOptions(opts...)
 
-		PortBindings: map[docker.Port][]docker.PortBinding{
-			"0/tcp": {{HostIP: "localhost", HostPort: "8000/tcp"}},
-		},
+	if endpoint := o.externalEndpoint(); endpoint != "" {
+		return externalDynamoDB(ctx, endpoint, o)
 	}
 
-	resource, err := pool.RunWithOptions(runOpt)
+	pool, err := dockertest.NewPool("")
 	if err != nil {
-		panic("Could not start DynamoDB Local " + err.Error())
+		return Client{}, nil, fmt.Errorf("%w: %w", ErrDockerUnavailable, err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		return Client{}, nil, fmt.Errorf("%w: %w", ErrDockerUnavailable, err)
 	}
 
-	port := resource.GetHostPort("8000/tcp")
-	fmt.Println("Using host:port of", port)
+	if name := o.reuseName(); name != "" {
+		return reuseDynamoDB(ctx, pool, o, name)
+	}
 
-	dynamoClient := createDB(pool, port)
-	client = Client{Client: dynamoClient, ContainerID: resource.Container.ID}
-	purge = func() {
+	resource, err := pool.RunWithOptions(o.runOptions(), o.hostConfig)
+	if err != nil {
+		return Client{}, nil, fmt.Errorf("%w: %w", ErrContainerStart, err)
+	}
 
+	purgeE := func() error {
 		if err := pool.Purge(resource); err != nil {
-			panic("Could not purge DynamoDB " + err.Error())
+			return fmt.Errorf("%w: %w", ErrContainerPurge, err)
 		}
+		return nil
+	}
+
+	endpoint := "http://" + resource.GetHostPort(dynamoDBLocalPort)
 
+	c, err := createDB(ctx, pool, resource, endpoint, o)
+	if err != nil {
+		// Do not leave a half started container behind.
+		_ = purgeE()
+		return Client{}, nil, err
 	}
 
-	return client, purge
+	return c, purgeE, nil
+}
+
+// externalDynamoDB connects to an endpoint that is already running, so there
+// is no container to purge.
+func externalDynamoDB(ctx context.Context, endpoint string, o *options) (Client, func() error, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	c, err := newClient(ctx, "", endpoint, o)
+	if err != nil {
+		return Client{}, nil, fmt.Errorf("%w: %w", ErrEndpointNotReady, err)
+	}
+	if err := waitReady(ctx, c.Client, o); err != nil {
+		return Client{}, nil, fmt.Errorf("%w: %s: %w", ErrEndpointNotReady, endpoint, err)
+	}
+
+	return c, func() error { return nil }, nil
 }
 
-func createDB(pool *dockertest.Pool, port string) *dynamodb.Client {
-	ctx := context.Background()
-	var dynamoClient *dynamodb.Client
-	err := pool.Retry(func() error {
-		cfg, err := config.LoadDefaultConfig(ctx,
-			config.WithRegion("us-east-1"),
-			config.WithCredentialsProvider(
-				credentials.StaticCredentialsProvider{
-					Value: aws.Credentials{
-						AccessKeyID: "dummy", SecretAccessKey: "dummy", SessionToken: "dummy",
-						Source: "Hard-coded credentials; values are irrelevant for local DynamoDB",
-					},
-				}),
-		)
-		if err != nil {
-			return err
+// createDB connects to DynamoDB Local running in the container and waits until
+// it answers requests. The container logs are included in the error when it
+// does not, as they usually tell why.
+func createDB(ctx context.Context, pool *dockertest.Pool, resource *dockertest.Resource, endpoint string, o *options) (Client, error) {
+	c, err := newClient(ctx, resource.Container.ID, endpoint, o)
+	if err != nil {
+		return Client{}, fmt.Errorf("%w: %w", ErrEndpointNotReady, err)
+	}
+	if err := waitReady(ctx, c.Client, o); err != nil {
+		return Client{}, fmt.Errorf("%w: %s: %w\ncontainer logs:\n%s",
+			ErrEndpointNotReady, endpoint, err, containerLogs(pool, resource.Container.ID))
+	}
+	return c, nil
+}
+
+// waitReady issues ListTables until it succeeds, as the JVM running DynamoDB
+// Local takes a while to boot after the container has started.
+func waitReady(ctx context.Context, dynamoClient *dynamodb.Client, o *options) error {
+	ctx, cancel := context.WithTimeout(ctx, o.readyTimeout)
+	defer cancel()
+
+	// Retries are done here, so each probe is a single attempt.
+	single := func(o *dynamodb.Options) { o.RetryMaxAttempts = 1 }
+	for {
+		_, err := dynamoClient.ListTables(internal(ctx), &dynamodb.ListTablesInput{Limit: aws.Int32(1)}, single)
+		if err == nil {
+			return nil
 		}
 
-		dynamoClient = dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
-			o.BaseEndpoint = aws.String("http://" + port)
-		})
-		return nil
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not ready after %s: %w", o.readyTimeout, err)
+		case <-time.After(o.readyInterval):
+		}
+	}
+}
+
+// containerLogs returns the last lines the container wrote.
+func containerLogs(pool *dockertest.Pool, containerID string) string {
+	var logs bytes.Buffer
+	err := pool.Client.Logs(docker.LogsOptions{
+		Container:    containerID,
+		OutputStream: &logs,
+		ErrorStream:  &logs,
+		Stdout:       true,
+		Stderr:       true,
+		Tail:         containerLogsTail,
 	})
 	if err != nil {
-		panic("Could not connect to the Docker instance of DynamoDB Local" + err.Error())
+		return fmt.Sprintf("could not read container logs: %v", err)
+	}
+	return logs.String()
+}
+
+// newDynamoClient creates a client for the endpoint with dummy credentials.
+func newDynamoClient(ctx context.Context, endpoint string, optFns ...func(*dynamodb.Options)) (*dynamodb.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion("us-east-1"),
+		config.WithCredentialsProvider(
+			credentials.StaticCredentialsProvider{
+				Value: aws.Credentials{
+					AccessKeyID: "dummy", SecretAccessKey: "dummy", SessionToken: "dummy",
+					Source: "Hard-coded credentials; values are irrelevant for local DynamoDB",
+				},
+			}),
+	)
+	if err != nil {
+		return nil, err
 	}
-	return dynamoClient
+
+	return dynamodb.NewFromConfig(cfg, append([]func(*dynamodb.Options){func(o *dynamodb.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	}}, optFns...)...), nil
 }
@@ -2,10 +2,18 @@ package dynamotest_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/docker/docker/client"
+	"github.com/google/go-cmp/cmp"
 
 	"github.com/rozen03/dynamotest"
 )
@@ -39,8 +47,24 @@ func checkDockerInstanceRemoved(containerID string) (bool, error) {
 	return false, err
 }
 
+// skipWithoutDocker skips the tests of NewDynamoDB and its clean-up function,
+// which panic when Docker is unavailable.
+func skipWithoutDocker(t *testing.T) {
+	t.Helper()
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err == nil {
+		defer cli.Close()
+		_, err = cli.Ping(context.Background())
+	}
+	if err != nil {
+		t.Skipf("Skipping test as Docker is unavailable: %v", err)
+	}
+}
+
 func TestDockerInstanceCreation(t *testing.T) {
 	t.Parallel()
+	skipWithoutDocker(t)
 
 	// Create DynamoDB client and start Docker instance
 	client, clean := dynamotest.NewDynamoDB()
@@ -78,6 +102,7 @@ func TestDockerInstanceDeletion(t *testing.T) {
 	// it is to ensure that the Docker instance is removed after the test is complete.
 	// just in case the other test is modified and the cleanup is not called.
 	t.Parallel()
+	skipWithoutDocker(t)
 
 	// Create DynamoDB client and start Docker instance
 	client, clean := dynamotest.NewDynamoDB()
@@ -100,3 +125,176 @@ func TestDockerInstanceDeletion(t *testing.T) {
 		t.Fatalf("Docker instance is still running after clean up")
 	}
 }
+
+func TestDockerInstanceWithOptions(t *testing.T) {
+	t.Parallel()
+	skipWithoutDocker(t)
+
+	dynamo, clean := dynamotest.NewDynamoDBWithOptions(
+		dynamotest.WithLabels(map[string]string{"dynamotest.test": "options"}),
+		dynamotest.WithJVMFlags("-Xmx256m"),
+		dynamotest.WithFlags("-inMemory", "-sharedDb"),
+	)
+	defer clean()
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		t.Fatalf("Error creating Docker client: %v", err)
+	}
+	defer cli.Close()
+
+	container, err := cli.ContainerInspect(context.Background(), dynamo.ContainerID)
+	if err != nil {
+		t.Fatalf("Error inspecting Docker instance: %v", err)
+	}
+
+	if got := container.Config.Labels["dynamotest.test"]; got != "options" {
+		t.Errorf("expected label to be 'options', got '%s'", got)
+	}
+	wantCmd := []string{"-Xmx256m", "-jar", "DynamoDBLocal.jar", "-inMemory", "-sharedDb"}
+	if diff := cmp.Diff(wantCmd, []string(container.Config.Cmd)); diff != "" {
+		t.Errorf("container command didn't match (-want / +got)\n%s", diff)
+	}
+}
+
+func TestNewDynamoDBE_DockerUnavailable(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	t.Setenv("DYNAMOTEST_ENDPOINT", "")
+	t.Setenv("DYNAMOTEST_REUSE", "")
+
+	_, _, err := dynamotest.NewDynamoDBE(context.Background())
+	if !errors.Is(err, dynamotest.ErrDockerUnavailable) {
+		t.Fatalf("expected ErrDockerUnavailable, got %v", err)
+	}
+}
+
+func TestDockerInstanceWithHostPort(t *testing.T) {
+	t.Parallel()
+	skipWithoutDocker(t)
+
+	// Find a free port to request from Docker.
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Error finding a free port: %v", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	dynamo, clean := dynamotest.NewDynamoDBWithOptions(dynamotest.WithHostPort(port))
+	defer clean()
+
+	if want := fmt.Sprintf(":%d", port); !strings.HasSuffix(dynamo.Endpoint, want) {
+		t.Errorf("expected endpoint to end with '%s', got '%s'", want, dynamo.Endpoint)
+	}
+
+	if _, err := dynamo.ListTables(context.Background(), &dynamodb.ListTablesInput{}); err != nil {
+		t.Errorf("failed to list tables: %v", err)
+	}
+}
+
+func TestDockerInstanceReuse(t *testing.T) {
+	t.Parallel()
+	skipWithoutDocker(t)
+
+	name := fmt.Sprintf("reuse-test-%d", time.Now().UnixNano())
+	first, cleanFirst := dynamotest.NewDynamoDBWithOptions(dynamotest.WithReuse(name))
+	second, cleanSecond := dynamotest.NewDynamoDBWithOptions(dynamotest.WithReuse(name))
+
+	if first.ContainerID != second.ContainerID {
+		t.Fatalf("expected the container to be shared, got '%s' and '%s'", first.ContainerID, second.ContainerID)
+	}
+
+	// The container must survive while it is still leased.
+	cleanFirst()
+	running, err := checkDockerInstanceRunning(second.ContainerID)
+	if err != nil {
+		t.Fatalf("Error checking if Docker instance is running: %v", err)
+	}
+	if !running {
+		t.Fatalf("Docker instance was removed while still in use")
+	}
+
+	cleanSecond()
+	time.Sleep(2 * time.Second)
+
+	removed, err := checkDockerInstanceRemoved(second.ContainerID)
+	if err != nil {
+		t.Fatalf("Error checking if Docker instance is removed: %v", err)
+	}
+	if !removed {
+		t.Fatalf("Docker instance is still running after the last clean up")
+	}
+}
+
+// newFakeEndpoint starts a server answering ListTables like an empty DynamoDB.
+func newFakeEndpoint(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		fmt.Fprint(w, `{"TableNames":[]}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewDynamoDBE_ExternalEndpoint(t *testing.T) {
+	// Docker must not be needed when an endpoint is given.
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	server := newFakeEndpoint(t)
+
+	dynamo, clean, err := dynamotest.NewDynamoDBE(context.Background(), dynamotest.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatalf("failed to connect to external endpoint: %v", err)
+	}
+	if dynamo.Endpoint != server.URL {
+		t.Errorf("expected endpoint '%s', got '%s'", server.URL, dynamo.Endpoint)
+	}
+	if dynamo.ContainerID != "" {
+		t.Errorf("expected no container, got '%s'", dynamo.ContainerID)
+	}
+	if err := clean(); err != nil {
+		t.Errorf("expected clean up to be a no-op, got %v", err)
+	}
+}
+
+func TestNewDynamoDBE_EndpointNotReady(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	_, _, err := dynamotest.NewDynamoDBE(context.Background(),
+		dynamotest.WithEndpoint(server.URL),
+		dynamotest.WithReadyTimeout(500*time.Millisecond),
+		dynamotest.WithReadyInterval(100*time.Millisecond),
+	)
+	if !errors.Is(err, dynamotest.ErrEndpointNotReady) {
+		t.Fatalf("expected ErrEndpointNotReady, got %v", err)
+	}
+}
+
+func TestNew_SkipsWithoutDocker(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "unix:///nonexistent/docker.sock")
+	t.Setenv("DYNAMOTEST_REQUIRE_DOCKER", "")
+	t.Setenv("DYNAMOTEST_ENDPOINT", "")
+	t.Setenv("DYNAMOTEST_REUSE", "")
+
+	var skipped bool
+	t.Run("new", func(t *testing.T) {
+		defer func() { skipped = t.Skipped() }()
+		dynamotest.New(t)
+	})
+	if !skipped {
+		t.Errorf("expected the test to be skipped without Docker")
+	}
+}
+
+func TestNew_ExternalEndpoint(t *testing.T) {
+	server := newFakeEndpoint(t)
+
+	dynamo := dynamotest.New(t, dynamotest.WithEndpoint(server.URL))
+	if dynamo.Endpoint != server.URL {
+		t.Errorf("expected endpoint '%s', got '%s'", server.URL, dynamo.Endpoint)
+	}
+}
@@ -0,0 +1,50 @@
+package dynamotest
+
+import "errors"
+
+var (
+	// ErrDockerUnavailable is returned when the Docker daemon cannot be reached.
+	ErrDockerUnavailable = errors.New("dynamotest: docker is unavailable")
+
+	// ErrContainerStart is returned when the DynamoDB Local container cannot be started.
+	ErrContainerStart = errors.New("dynamotest: could not start DynamoDB Local container")
+
+	// ErrEndpointNotReady is returned when the DynamoDB endpoint does not become usable.
+	ErrEndpointNotReady = errors.New("dynamotest: DynamoDB endpoint is not ready")
+
+	// ErrContainerPurge is returned when the container cannot be removed.
+	ErrContainerPurge = errors.New("dynamotest: could not purge DynamoDB Local container")
+
+	// ErrTableName is returned when a random table name cannot be generated.
+	ErrTableName = errors.New("dynamotest: could not generate table name")
+
+	// ErrTableCreate is returned when the testing table cannot be created.
+	ErrTableCreate = errors.New("dynamotest: could not create table")
+
+	// ErrTableNotActive is returned when the table or one of its indexes does
+	// not become ACTIVE in time.
+	ErrTableNotActive = errors.New("dynamotest: table is not active")
+
+	// ErrSchemaSource is returned when a schema cannot be loaded from a
+	// template or definition file.
+	ErrSchemaSource = errors.New("dynamotest: could not load schema")
+
+	// ErrInvalidSchema is returned when ValidateSchema finds problems in the
+	// schema of a testing table.
+	ErrInvalidSchema = errors.New("dynamotest: invalid table schema")
+
+	// ErrFixture is returned when a fixture file cannot be read or parsed.
+	ErrFixture = errors.New("dynamotest: could not load fixture")
+
+	// ErrSeedMarshal is returned when initial data cannot be marshalled into an item.
+	ErrSeedMarshal = errors.New("dynamotest: could not marshal initial data")
+
+	// ErrSeedWrite is returned when initial data cannot be written to the table.
+	ErrSeedWrite = errors.New("dynamotest: could not write initial data")
+
+	// ErrTableReset is returned when the items of a table cannot be deleted.
+	ErrTableReset = errors.New("dynamotest: could not reset table")
+
+	// ErrTableDump is returned when the items of a table cannot be read.
+	ErrTableDump = errors.New("dynamotest: could not dump table")
+)
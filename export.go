@@ -0,0 +1,265 @@
+package dynamotest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	exportSummaryFile = "manifest-summary.json"
+	exportFilesFile   = "manifest-files.json"
+	pipelineManifest  = "manifest"
+	exportFormatJSON  = "DYNAMODB_JSON"
+	exportFormatIon   = "ION"
+)
+
+// SeedFromExport writes the items of a DynamoDB export to an existing table,
+// so production shaped samples can be replayed locally. See LoadExport for
+// the supported exports.
+func (c Client) SeedFromExport(t *testing.T, table, dir string) {
+	t.Helper()
+
+	if err := c.SeedFromExportE(context.Background(), table, dir); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Logf("Table '%s' has been seeded from export '%s'", table, dir)
+}
+
+// SeedFromExportE works like SeedFromExport, but returns an error instead of
+// failing the test. The error wraps ErrFixture or ErrSeedWrite.
+func (c Client) SeedFromExportE(ctx context.Context, table, dir string) error {
+	items, err := LoadExport(dir)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return c.writeItems(ctx, table, items)
+}
+
+// LoadExport reads the items of a DynamoDB export downloaded to dir.
+//
+// dir is either the directory of an export to S3, the one holding
+// manifest-summary.json, manifest-files.json and data/, in the DYNAMODB_JSON
+// or ION format, or the output directory of a Data Pipeline export, holding a
+// manifest file and the data files. Data files may be gzipped or not.
+func LoadExport(dir string) ([]map[string]types.AttributeValue, error) {
+	if _, err := os.Stat(filepath.Join(dir, exportSummaryFile)); err == nil {
+		return loadS3Export(dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, pipelineManifest)); err == nil {
+		return loadPipelineExport(dir)
+	}
+	return nil, fmt.Errorf("%w: %s: neither %s nor %s found, expected a DynamoDB export directory",
+		ErrFixture, dir, exportSummaryFile, pipelineManifest)
+}
+
+func loadS3Export(dir string) ([]map[string]types.AttributeValue, error) {
+	summaryPath := filepath.Join(dir, exportSummaryFile)
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFixture, err)
+	}
+	var summary struct {
+		OutputFormat string `json:"outputFormat"`
+		ItemCount    *int   `json:"itemCount"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrFixture, summaryPath, err)
+	}
+
+	var parse func(path string, data []byte) ([]map[string]types.AttributeValue, error)
+	switch summary.OutputFormat {
+	case exportFormatJSON, "":
+		parse = parseExportJSON
+	case exportFormatIon:
+		parse = parseExportIon
+	default:
+		return nil, fmt.Errorf("%w: %s: unsupported output format '%s', expected %s or %s",
+			ErrFixture, summaryPath, summary.OutputFormat, exportFormatJSON, exportFormatIon)
+	}
+
+	files, err := exportDataFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, file := range files {
+		data, err := readExportFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileItems, err := parse(file, data)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, fileItems...)
+	}
+
+	// A missing data file would otherwise silently seed fewer items.
+	if summary.ItemCount != nil && *summary.ItemCount != len(items) {
+		return nil, fmt.Errorf("%w: %s: the export has %d items, but %d were found in %s",
+			ErrFixture, summaryPath, *summary.ItemCount, len(items), filepath.Join(dir, "data"))
+	}
+	return items, nil
+}
+
+// exportDataFiles lists the data files of an export to S3, from
+// manifest-files.json when present.
+func exportDataFiles(dir string) ([]string, error) {
+	manifestPath := filepath.Join(dir, exportFilesFile)
+	data, err := os.ReadFile(manifestPath)
+	if errors.Is(err, os.ErrNotExist) {
+		files, err := filepath.Glob(filepath.Join(dir, "data", "*"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrFixture, err)
+		}
+		return files, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFixture, err)
+	}
+
+	// The manifest holds a JSON object per data file, with its S3 key.
+	var files []string
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var entry struct {
+			DataFileS3Key string `json:"dataFileS3Key"`
+		}
+		if err := dec.Decode(&entry); err == io.EOF {
+			return files, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFixture, manifestPath, err)
+		}
+		files = append(files, filepath.Join(dir, "data", path.Base(entry.DataFileS3Key)))
+	}
+}
+
+func readExportFile(file string) ([]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFixture, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFixture, file, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrFixture, file, err)
+	}
+	return data, nil
+}
+
+// parseExportJSON parses DYNAMODB_JSON data, an {"Item": {...}} object per
+// line.
+func parseExportJSON(path string, data []byte) ([]map[string]types.AttributeValue, error) {
+	return parseJSONLines(path, data, func(object map[string]any) (map[string]types.AttributeValue, error) {
+		item, ok := object["Item"].(map[string]any)
+		if !ok {
+			return nil, errors.New(`expected an {"Item": {...}} object`)
+		}
+		return typedItem(item)
+	})
+}
+
+func loadPipelineExport(dir string) ([]map[string]types.AttributeValue, error) {
+	manifestPath := filepath.Join(dir, pipelineManifest)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFixture, err)
+	}
+	var manifest struct {
+		Entries []struct {
+			URL string `json:"url"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrFixture, manifestPath, err)
+	}
+
+	var items []map[string]types.AttributeValue
+	for _, entry := range manifest.Entries {
+		file := filepath.Join(dir, path.Base(entry.URL))
+		data, err := readExportFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileItems, err := parseJSONLines(file, data, func(object map[string]any) (map[string]types.AttributeValue, error) {
+			return typedItem(pipelineItem(object))
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, fileItems...)
+	}
+	return items, nil
+}
+
+// pipelineTypes maps the type descriptors of Data Pipeline exports, named
+// after the fields of the old Java SDK, to the DynamoDB JSON ones.
+var pipelineTypes = map[string]string{
+	"s": "S", "n": "N", "b": "B", "bOOL": "BOOL", "nULLValue": "NULL",
+	"sS": "SS", "nS": "NS", "bS": "BS", "l": "L", "m": "M",
+}
+
+// pipelineItem converts a Data Pipeline item into DynamoDB JSON.
+func pipelineItem(object map[string]any) map[string]any {
+	out := make(map[string]any, len(object))
+	for name, v := range object {
+		out[name] = pipelineValue(v)
+	}
+	return out
+}
+
+func pipelineValue(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return v
+	}
+	for k, value := range m {
+		attrType, ok := pipelineTypes[k]
+		if !ok {
+			return v
+		}
+		switch attrType {
+		case "M":
+			if nested, ok := value.(map[string]any); ok {
+				value = pipelineItem(nested)
+			}
+		case "L":
+			if list, ok := value.([]any); ok {
+				converted := make([]any, len(list))
+				for i, e := range list {
+					converted[i] = pipelineValue(e)
+				}
+				value = converted
+			}
+		}
+		return map[string]any{attrType: value}
+	}
+	return v
+}
@@ -0,0 +1,469 @@
+package dynamotest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The ION export format is Amazon Ion text. Only the subset DynamoDB writes is
+// read here, which avoids depending on a full Ion implementation: structs,
+// lists, strings, symbols, numbers, booleans, nulls and blobs, with sets
+// written as lists annotated with their type.
+
+const ionVersionMarker = "$ion_1_0"
+
+// ionSetTypes maps the annotations of the lists holding sets to their type.
+var ionSetTypes = map[string]string{
+	"$dynamodb_SS": "SS",
+	"$dynamodb_NS": "NS",
+	"$dynamodb_BS": "BS",
+}
+
+// parseExportIon parses ION data, an {Item: {...}} struct per item.
+func parseExportIon(path string, data []byte) ([]map[string]types.AttributeValue, error) {
+	r := &ionReader{data: data}
+
+	var items []map[string]types.AttributeValue
+	for {
+		if err := r.skipSpace(); err != nil {
+			return nil, fixtureError(path, r.line(), err)
+		}
+		if r.eof() {
+			return items, nil
+		}
+		if r.hasPrefix(ionVersionMarker) {
+			r.pos += len(ionVersionMarker)
+			continue
+		}
+
+		line := r.line()
+		v, err := r.value()
+		if err != nil {
+			return nil, fixtureError(path, r.line(), err)
+		}
+		record, ok := v.(*types.AttributeValueMemberM)
+		if !ok {
+			return nil, fixtureError(path, line, errors.New("expected an {Item: {...}} struct"))
+		}
+		item, ok := record.Value["Item"].(*types.AttributeValueMemberM)
+		if !ok {
+			return nil, fixtureError(path, line, errors.New("expected an {Item: {...}} struct"))
+		}
+		items = append(items, item.Value)
+	}
+}
+
+type ionReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *ionReader) line() int {
+	return lineAt(r.data, r.pos)
+}
+
+func (r *ionReader) eof() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *ionReader) peek() byte {
+	if r.eof() {
+		return 0
+	}
+	return r.data[r.pos]
+}
+
+func (r *ionReader) hasPrefix(s string) bool {
+	return bytes.HasPrefix(r.data[r.pos:], []byte(s))
+}
+
+// skipSpace skips whitespace and comments.
+func (r *ionReader) skipSpace() error {
+	for !r.eof() {
+		switch {
+		case strings.IndexByte(" \t\r\n\f\v", r.peek()) >= 0:
+			r.pos++
+		case r.hasPrefix("//"):
+			end := bytes.IndexByte(r.data[r.pos:], '\n')
+			if end < 0 {
+				r.pos = len(r.data)
+			} else {
+				r.pos += end + 1
+			}
+		case r.hasPrefix("/*"):
+			end := bytes.Index(r.data[r.pos+2:], []byte("*/"))
+			if end < 0 {
+				return errors.New("unterminated comment")
+			}
+			r.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// value reads a value together with its annotations.
+func (r *ionReader) value() (types.AttributeValue, error) {
+	var annotations []string
+	for {
+		if err := r.skipSpace(); err != nil {
+			return nil, err
+		}
+		start := r.pos
+		symbol, ok, err := r.symbol()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		if err := r.skipSpace(); err != nil {
+			return nil, err
+		}
+		if !r.hasPrefix("::") {
+			r.pos = start
+			break
+		}
+		r.pos += 2
+		annotations = append(annotations, symbol)
+	}
+
+	v, err := r.datum()
+	if err != nil {
+		return nil, err
+	}
+	for _, annotation := range annotations {
+		if setType, ok := ionSetTypes[annotation]; ok {
+			return ionSet(setType, annotation, v)
+		}
+	}
+	return v, nil
+}
+
+func (r *ionReader) datum() (types.AttributeValue, error) {
+	c := r.peek()
+	switch {
+	case r.eof():
+		return nil, errors.New("unexpected end of data")
+	case r.hasPrefix("{{"):
+		return r.blob()
+	case c == '{':
+		return r.structValue()
+	case c == '[':
+		return r.list()
+	case c == '"':
+		s, err := r.quoted('"')
+		return &types.AttributeValueMemberS{Value: s}, err
+	case r.hasPrefix("'''"):
+		return nil, errors.New("long strings are not supported")
+	case c == '\'':
+		s, err := r.quoted('\'')
+		return &types.AttributeValueMemberS{Value: s}, err
+	case c == '-' || c == '+' || isDigit(c):
+		return r.number()
+	case isIdentStart(c):
+		word := r.identifier()
+		switch word {
+		case "true", "false":
+			return &types.AttributeValueMemberBOOL{Value: word == "true"}, nil
+		case "null":
+			// Typed nulls such as null.string are nulls all the same.
+			if r.peek() == '.' {
+				r.pos++
+				r.identifier()
+			}
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		case "nan":
+			return nil, errors.New("nan is not a valid number")
+		}
+		return &types.AttributeValueMemberS{Value: word}, nil
+	}
+	return nil, fmt.Errorf("unexpected character %q", c)
+}
+
+// symbol reads an identifier or quoted symbol, reporting false when the next
+// token is neither.
+func (r *ionReader) symbol() (string, bool, error) {
+	c := r.peek()
+	switch {
+	case c == '\'' && !r.hasPrefix("'''"):
+		s, err := r.quoted('\'')
+		return s, err == nil, err
+	case isIdentStart(c):
+		return r.identifier(), true, nil
+	}
+	return "", false, nil
+}
+
+func (r *ionReader) identifier() string {
+	start := r.pos
+	for !r.eof() && (isIdentStart(r.peek()) || isDigit(r.peek())) {
+		r.pos++
+	}
+	return string(r.data[start:r.pos])
+}
+
+func (r *ionReader) structValue() (types.AttributeValue, error) {
+	r.pos++
+	fields := make(map[string]types.AttributeValue)
+	for {
+		if err := r.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r.peek() == '}' {
+			r.pos++
+			return &types.AttributeValueMemberM{Value: fields}, nil
+		}
+
+		var (
+			name string
+			ok   = true
+			err  error
+		)
+		if r.peek() == '"' {
+			name, err = r.quoted('"')
+		} else {
+			name, ok, err = r.symbol()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("expected a field name, got %q", r.peek())
+		}
+		if err := r.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r.peek() != ':' {
+			return nil, fmt.Errorf("expected ':' after field '%s'", name)
+		}
+		r.pos++
+
+		v, err := r.value()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fields[name] = v
+
+		if err := r.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *ionReader) list() (types.AttributeValue, error) {
+	r.pos++
+	var values []types.AttributeValue
+	for {
+		if err := r.skipSpace(); err != nil {
+			return nil, err
+		}
+		if r.peek() == ']' {
+			r.pos++
+			return &types.AttributeValueMemberL{Value: values}, nil
+		}
+
+		v, err := r.value()
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", len(values), err)
+		}
+		values = append(values, v)
+
+		if err := r.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes the comma after a container element, if any.
+func (r *ionReader) separator(closing byte) error {
+	if err := r.skipSpace(); err != nil {
+		return err
+	}
+	switch r.peek() {
+	case ',':
+		r.pos++
+	case closing:
+	default:
+		return fmt.Errorf("expected ',' or '%c', got %q", closing, r.peek())
+	}
+	return nil
+}
+
+func (r *ionReader) blob() (types.AttributeValue, error) {
+	r.pos += 2
+	end := bytes.Index(r.data[r.pos:], []byte("}}"))
+	if end < 0 {
+		return nil, errors.New("unterminated blob")
+	}
+	content := strings.Join(strings.Fields(string(r.data[r.pos:r.pos+end])), "")
+	b, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("blob is not base64: %w", err)
+	}
+	r.pos += end + 2
+	return &types.AttributeValueMemberB{Value: b}, nil
+}
+
+// number reads an int, decimal or float, converting it to the DynamoDB number
+// syntax.
+func (r *ionReader) number() (types.AttributeValue, error) {
+	start := r.pos
+	for !r.eof() && (isIdentStart(r.peek()) || isDigit(r.peek()) || strings.IndexByte(".+-", r.peek()) >= 0) {
+		r.pos++
+	}
+	text := strings.ReplaceAll(string(r.data[start:r.pos]), "_", "")
+
+	unsigned := strings.ToLower(strings.TrimLeft(text, "+-"))
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0b") {
+		n, ok := new(big.Int).SetString(text, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid number '%s'", text)
+		}
+		return &types.AttributeValueMemberN{Value: n.String()}, nil
+	}
+
+	// Decimals use d as exponent and may end with a point, e.g. 36. or 1.5d3.
+	n := strings.NewReplacer("d", "e", "D", "e").Replace(text)
+	n = strings.Replace(n, ".e", "e", 1)
+	n = strings.TrimSuffix(n, ".")
+	if _, ok := new(big.Rat).SetString(n); !ok {
+		return nil, fmt.Errorf("invalid number '%s'", text)
+	}
+	return &types.AttributeValueMemberN{Value: n}, nil
+}
+
+// quoted reads a string or a quoted symbol, decoding its escapes.
+func (r *ionReader) quoted(quote byte) (string, error) {
+	r.pos++
+	var b strings.Builder
+	for {
+		if r.eof() {
+			return "", errors.New("unterminated string")
+		}
+		c := r.data[r.pos]
+		r.pos++
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if err := r.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+var ionEscapes = map[byte]string{
+	'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v", '0': "\x00",
+	'\\': "\\", '"': "\"", '\'': "'", '/': "/", '?': "?", '\n': "",
+}
+
+func (r *ionReader) escape(b *strings.Builder) error {
+	if r.eof() {
+		return errors.New("unterminated string")
+	}
+	c := r.data[r.pos]
+	r.pos++
+	if s, ok := ionEscapes[c]; ok {
+		b.WriteString(s)
+		return nil
+	}
+
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if digits == 0 {
+		return fmt.Errorf("invalid escape '\\%c'", c)
+	}
+	code, err := r.hex(digits)
+	if err != nil {
+		return err
+	}
+	// Characters outside the basic plane may be written as surrogate pairs.
+	rn := rune(code)
+	if utf16.IsSurrogate(rn) && r.hasPrefix(`\u`) {
+		r.pos += 2
+		low, err := r.hex(4)
+		if err != nil {
+			return err
+		}
+		rn = utf16.DecodeRune(rn, rune(low))
+	}
+	if !utf8.ValidRune(rn) {
+		return fmt.Errorf("invalid character code %x", code)
+	}
+	b.WriteRune(rn)
+	return nil
+}
+
+func (r *ionReader) hex(digits int) (uint64, error) {
+	if r.pos+digits > len(r.data) {
+		return 0, errors.New("unterminated escape")
+	}
+	code, err := strconv.ParseUint(string(r.data[r.pos:r.pos+digits]), 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid escape: %w", err)
+	}
+	r.pos += digits
+	return code, nil
+}
+
+// ionSet converts a list annotated as a set.
+func ionSet(setType, annotation string, v types.AttributeValue) (types.AttributeValue, error) {
+	list, ok := v.(*types.AttributeValueMemberL)
+	if !ok {
+		return nil, fmt.Errorf("%s annotates a value that is not a list", annotation)
+	}
+
+	switch setType {
+	case "SS":
+		set := &types.AttributeValueMemberSS{}
+		for _, e := range list.Value {
+			s, ok := e.(*types.AttributeValueMemberS)
+			if !ok {
+				return nil, fmt.Errorf("%s holds a value that is not a string", annotation)
+			}
+			set.Value = append(set.Value, s.Value)
+		}
+		return set, nil
+	case "NS":
+		set := &types.AttributeValueMemberNS{}
+		for _, e := range list.Value {
+			n, ok := e.(*types.AttributeValueMemberN)
+			if !ok {
+				return nil, fmt.Errorf("%s holds a value that is not a number", annotation)
+			}
+			set.Value = append(set.Value, n.Value)
+		}
+		return set, nil
+	default: // BS
+		set := &types.AttributeValueMemberBS{}
+		for _, e := range list.Value {
+			b, ok := e.(*types.AttributeValueMemberB)
+			if !ok {
+				return nil, fmt.Errorf("%s holds a value that is not a blob", annotation)
+			}
+			set.Value = append(set.Value, b.Value)
+		}
+		return set, nil
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,187 @@
+package dynamotest_test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestLoadExport(t *testing.T) {
+	t.Parallel()
+
+	want := []map[string]types.AttributeValue{
+		{
+			"id":     &types.AttributeValueMemberS{Value: "1"},
+			"age":    &types.AttributeValueMemberN{Value: "36"},
+			"tags":   &types.AttributeValueMemberSS{Value: []string{"admin", "dev"}},
+			"avatar": &types.AttributeValueMemberB{Value: []byte("hello")},
+		},
+		{
+			"id":  &types.AttributeValueMemberS{Value: "2"},
+			"age": &types.AttributeValueMemberN{Value: "54"},
+			"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"city": &types.AttributeValueMemberS{Value: "Portland"},
+			}},
+			"deleted": &types.AttributeValueMemberNULL{Value: true},
+		},
+	}
+
+	cases := map[string]string{
+		"s3 export in DYNAMODB_JSON": "testdata/export/json",
+		"s3 export in ION":           "testdata/export/ion",
+		"data pipeline export":       "testdata/export/pipeline",
+	}
+
+	for name, dir := range cases {
+		dir := dir
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := dynamotest.LoadExport(dir)
+			if err != nil {
+				t.Fatalf("failed to load export: %v", err)
+			}
+			// Exports are not ordered.
+			sort.Slice(got, func(i, j int) bool {
+				return got[i]["id"].(*types.AttributeValueMemberS).Value < got[j]["id"].(*types.AttributeValueMemberS).Value
+			})
+
+			if diff := cmp.Diff(want, got, avCmpOpts); diff != "" {
+				t.Errorf("items didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadExport_Ion(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeIonExport(t, dir, `$ion_1_0 {Item:{
+		'id':"a\"bé",
+		count:-1_000,
+		price:1.50d2,
+		ratio:2.5e0,
+		scores:$dynamodb_NS::[1., 2.5],
+		blobs:$dynamodb_BS::[{{ aGk= }}],
+		active:true,
+		list:[null.string, "x"] // comments are allowed
+	}}`)
+
+	got, err := dynamotest.LoadExport(dir)
+	if err != nil {
+		t.Fatalf("failed to load export: %v", err)
+	}
+
+	want := []map[string]types.AttributeValue{
+		{
+			"id":     &types.AttributeValueMemberS{Value: "a\"bé"},
+			"count":  &types.AttributeValueMemberN{Value: "-1000"},
+			"price":  &types.AttributeValueMemberN{Value: "1.50e2"},
+			"ratio":  &types.AttributeValueMemberN{Value: "2.5e0"},
+			"scores": &types.AttributeValueMemberNS{Value: []string{"1", "2.5"}},
+			"blobs":  &types.AttributeValueMemberBS{Value: [][]byte{[]byte("hi")}},
+			"active": &types.AttributeValueMemberBOOL{Value: true},
+			"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberNULL{Value: true},
+				&types.AttributeValueMemberS{Value: "x"},
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, got, avCmpOpts); diff != "" {
+		t.Errorf("items didn't match (-want / +got)\n%s", diff)
+	}
+}
+
+func TestLoadExport_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeIonExport(t, dir, "$ion_1_0 {Item:{id:\"1\"}}\n$ion_1_0 {Item:{id:\"2\", tags:$dynamodb_SS::[1]}}\n")
+
+	_, err := dynamotest.LoadExport(dir)
+	if !errors.Is(err, dynamotest.ErrFixture) {
+		t.Fatalf("expected ErrFixture, got %v", err)
+	}
+	want := "data.ion.gz:2: Item: tags: $dynamodb_SS holds a value that is not a string"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error to contain '%s', got '%v'", want, err)
+	}
+
+	_, err = dynamotest.LoadExport("testdata/fixtures")
+	if !errors.Is(err, dynamotest.ErrFixture) {
+		t.Fatalf("expected ErrFixture for a directory without export, got %v", err)
+	}
+}
+
+// writeIonExport writes an ION export without manifest-files.json, so the
+// data directory is read as a whole.
+func writeIonExport(t *testing.T, dir, data string) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(dir, "manifest-summary.json"), []byte(`{"outputFormat":"ION"}`), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "data"), 0o755); err != nil {
+		t.Fatalf("failed to create data directory: %v", err)
+	}
+
+	f, err := os.Create(filepath.Join(dir, "data", "data.ion.gz"))
+	if err != nil {
+		t.Fatalf("failed to create data file: %v", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatalf("failed to write data file: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to write data file: %v", err)
+	}
+}
+
+func TestSeedFromExport(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	table := client.CreateTestingTable(t, "export", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
+	client.SeedFromExport(t, table, "testdata/export/ion")
+
+	out, err := client.Scan(context.Background(), &dynamodb.ScanInput{
+		TableName: aws.String(table),
+		Select:    types.SelectCount,
+	})
+	if err != nil {
+		t.Fatalf("failed to scan table: %v", err)
+	}
+	if out.Count != 2 {
+		t.Errorf("expected 2 items, got %d", out.Count)
+	}
+}
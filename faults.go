@@ -0,0 +1,168 @@
+package dynamotest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Fault describes requests the FaultInjector fails or delays.
+type Fault struct {
+	// Operation is the name of the operation, e.g. "PutItem". Empty matches
+	// every operation.
+	Operation string
+
+	// Table is the table of the request. Empty matches every table.
+	Table string
+
+	// After is how many matching requests go through untouched before the
+	// fault applies, so 2 fails the 3rd one.
+	After int
+
+	// Times is how many matching requests the fault applies to once After
+	// have gone through. Zero applies it to all of them.
+	Times int
+
+	// Latency delays the requests before they are sent or failed. Requests
+	// whose context ends first fail with the context error, like a timeout.
+	Latency time.Duration
+
+	// Err is returned instead of sending the request, e.g.
+	// &types.ProvisionedThroughputExceededException{}. Nil sends the request.
+	Err error
+}
+
+// FaultInjector fails or delays the requests of a Client, to test how code
+// handles errors DynamoDB Local never returns on its own, such as throttling.
+//
+// Faults apply to every attempt of a request, so the retries of the SDK are
+// counted as requests of their own, and the harness' own requests, such as
+// creating or seeding tables, are left alone. As a Client is usually shared
+// by parallel tests, faults should set Table to the table of the test.
+type FaultInjector struct {
+	mu       sync.Mutex
+	rules    []*faultRule
+	injected map[string]int
+}
+
+type faultRule struct {
+	Fault
+	seen int
+}
+
+// Inject adds faults until the test completes. When several faults match a
+// request, the first one added applies.
+func (f *FaultInjector) Inject(t *testing.T, faults ...Fault) {
+	t.Helper()
+
+	if f == nil {
+		t.Fatalf("the client has no fault injector, create it with NewDynamoDB or New")
+	}
+
+	rules := make([]*faultRule, len(faults))
+	for i, fault := range faults {
+		rules[i] = &faultRule{Fault: fault}
+	}
+
+	f.mu.Lock()
+	f.rules = append(f.rules, rules...)
+	f.mu.Unlock()
+
+	t.Cleanup(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.rules = slices.DeleteFunc(f.rules, func(r *faultRule) bool { return slices.Contains(rules, r) })
+	})
+}
+
+// Injected returns how many requests of the operation have been failed or
+// delayed, or of every operation when it is empty.
+func (f *FaultInjector) Injected(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if operation != "" {
+		return f.injected[operation]
+	}
+	total := 0
+	for _, n := range f.injected {
+		total += n
+	}
+	return total
+}
+
+// Reset removes every fault and the counts of Injected.
+func (f *FaultInjector) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.rules = nil
+	f.injected = nil
+}
+
+// addMiddleware applies the faults after the retry middleware, so that every
+// attempt goes through them.
+func (f *FaultInjector) addMiddleware(stack *middleware.Stack) error {
+	return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("dynamotest.Faults",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if isInternal(ctx) {
+				return next.HandleFinalize(ctx, in)
+			}
+
+			fault, ok := f.match(awsmiddleware.GetOperationName(ctx), requestTables(operationInput(ctx)))
+			if !ok {
+				return next.HandleFinalize(ctx, in)
+			}
+
+			if fault.Latency > 0 {
+				select {
+				case <-ctx.Done():
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, ctx.Err()
+				case <-time.After(fault.Latency):
+				}
+			}
+			if fault.Err != nil {
+				return middleware.FinalizeOutput{}, middleware.Metadata{}, fault.Err
+			}
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+}
+
+// match counts the request against every fault, returning the first one that
+// applies to it.
+func (f *FaultInjector) match(operation string, tables []string) (Fault, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var (
+		fault Fault
+		found bool
+	)
+	for _, r := range f.rules {
+		if r.Operation != "" && r.Operation != operation {
+			continue
+		}
+		if r.Table != "" && !slices.Contains(tables, r.Table) {
+			continue
+		}
+
+		r.seen++
+		if found || r.seen <= r.After || (r.Times > 0 && r.seen > r.After+r.Times) {
+			continue
+		}
+		fault, found = r.Fault, true
+	}
+
+	if found {
+		if f.injected == nil {
+			f.injected = map[string]int{}
+		}
+		f.injected[operation]++
+	}
+	return fault, found
+}
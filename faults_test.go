@@ -0,0 +1,134 @@
+package dynamotest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/rozen03/dynamotest"
+)
+
+var faultSchema = dynamodb.CreateTableInput{
+	AttributeDefinitions: []types.AttributeDefinition{
+		{
+			AttributeName: aws.String("id"),
+			AttributeType: types.ScalarAttributeTypeS,
+		},
+	},
+	KeySchema: []types.KeySchemaElement{
+		{
+			AttributeName: aws.String("id"),
+			KeyType:       types.KeyTypeHash,
+		},
+	},
+}
+
+func TestFaultInjector(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	noRetries := func(o *dynamodb.Options) { o.RetryMaxAttempts = 1 }
+	putItem := func(table, id string) error {
+		_, err := client.PutItem(context.Background(), &dynamodb.PutItemInput{
+			TableName: aws.String(table),
+			Item:      map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+		}, noRetries)
+		return err
+	}
+	fastRetries := func(o *dynamodb.Options) { o.Retryer = retry.AddWithMaxBackoffDelay(o.Retryer, 10*time.Millisecond) }
+	getItem := func(ctx context.Context, table string) error {
+		_, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName: aws.String(table),
+			Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+		}, fastRetries)
+		return err
+	}
+
+	t.Run("fail the 3rd request on a table", func(t *testing.T) {
+		table := client.CreateTestingTable(t, "faults", faultSchema)
+		other := client.CreateTestingTable(t, "faults", faultSchema)
+
+		client.Faults.Inject(t, dynamotest.Fault{
+			Operation: "PutItem",
+			Table:     table,
+			After:     2,
+			Times:     1,
+			Err:       &types.ProvisionedThroughputExceededException{Message: aws.String("injected")},
+		})
+
+		for i, id := range []string{"1", "2", "3", "4"} {
+			err := putItem(table, id)
+			var throttled *types.ProvisionedThroughputExceededException
+			if got, want := errors.As(err, &throttled), i == 2; got != want {
+				t.Errorf("request %d: expected throttling %v, got error %v", i+1, want, err)
+			}
+			if err := putItem(other, id); err != nil {
+				t.Errorf("expected other table to be left alone, got %v", err)
+			}
+		}
+		if got := client.Faults.Injected("PutItem"); got != 1 {
+			t.Errorf("expected 1 injected fault, got %d", got)
+		}
+		// Seeding and dumping tables are not affected.
+		dynamotest.AssertItemCount(t, client, table, 3)
+	})
+
+	t.Run("retries", func(t *testing.T) {
+		client.Faults.Reset()
+		table := client.CreateTestingTable(t, "faults", faultSchema)
+
+		client.Faults.Inject(t, dynamotest.Fault{
+			Operation: "GetItem",
+			Times:     2,
+			Err:       &types.ProvisionedThroughputExceededException{Message: aws.String("injected")},
+		})
+
+		if err := getItem(context.Background(), table); err != nil {
+			t.Errorf("expected the SDK to retry, got %v", err)
+		}
+		if got := client.Faults.Injected("GetItem"); got != 2 {
+			t.Errorf("expected 2 injected faults, got %d", got)
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		table := client.CreateTestingTable(t, "faults", faultSchema)
+
+		client.Faults.Inject(t, dynamotest.Fault{Operation: "GetItem", Table: table, Latency: 200 * time.Millisecond})
+
+		start := time.Now()
+		if err := getItem(context.Background(), table); err != nil {
+			t.Errorf("expected delayed request to succeed, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("expected request to take at least 200ms, took %s", elapsed)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if err := getItem(ctx, table); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected request to time out, got %v", err)
+		}
+	})
+
+	t.Run("removed once the test completes", func(t *testing.T) {
+		table := client.CreateTestingTable(t, "faults", faultSchema)
+
+		t.Run("inject", func(t *testing.T) {
+			client.Faults.Inject(t, dynamotest.Fault{Table: table, Err: errors.New("injected")})
+			if err := putItem(table, "1"); err == nil {
+				t.Errorf("expected injected error")
+			}
+		})
+		if err := putItem(table, "1"); err != nil {
+			t.Errorf("expected fault to be removed, got %v", err)
+		}
+	})
+}
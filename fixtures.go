@@ -0,0 +1,546 @@
+package dynamotest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+)
+
+// CreateTestingTableFromFixtures works like CreateTestingTable, seeding the
+// table with the items of the fixture files instead of Go values. See
+// LoadFixtures for the supported formats.
+func (c Client) CreateTestingTableFromFixtures(t *testing.T, tablePrefix string, schema dynamodb.CreateTableInput, paths ...string) string {
+	t.Helper()
+
+	var initialData []any
+	for _, path := range paths {
+		items, err := LoadFixtures(path)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		for _, item := range items {
+			initialData = append(initialData, item)
+		}
+	}
+
+	return c.CreateTestingTable(t, tablePrefix, schema, initialData...)
+}
+
+// SeedFromFile writes the items of a fixture file to an existing table. See
+// LoadFixtures for the supported formats.
+func (c Client) SeedFromFile(t *testing.T, table, path string) {
+	t.Helper()
+
+	if err := c.SeedFromFileE(context.Background(), table, path); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Logf("Table '%s' has been seeded from '%s'", table, path)
+}
+
+// SeedFromFileE works like SeedFromFile, but returns an error instead of
+// failing the test. The error wraps ErrFixture or ErrSeedWrite.
+func (c Client) SeedFromFileE(ctx context.Context, table, path string) error {
+	items, err := LoadFixtures(path)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return c.writeItems(ctx, table, items)
+}
+
+// LoadFixtures reads the items of a fixture file, picking the format from its
+// extension:
+//
+//   - .json holds an array of items, or a single one.
+//   - .jsonl and .ndjson hold an item per line.
+//   - .yaml and .yml hold a sequence of items, or a single one, per document.
+//   - .csv holds a row with the attribute names, a row with their types (S, N,
+//     B, BOOL, NULL, SS, NS, BS, L or M) and then an item per row. Empty cells
+//     are left out of the item, B cells are base64 and set, L and M cells are
+//     JSON.
+//
+// JSON and YAML items are plain objects marshalled with attributevalue, unless
+// every attribute is written in the DynamoDB JSON form, e.g. {"S": "value"},
+// in which case they are read as such. Errors wrap ErrFixture and point to the
+// file and line of the offending item.
+func LoadFixtures(path string) ([]map[string]types.AttributeValue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFixture, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return loadJSONFixture(path, data)
+	case ".jsonl", ".ndjson":
+		return loadJSONLFixture(path, data)
+	case ".yaml", ".yml":
+		return loadYAMLFixture(path, data)
+	case ".csv":
+		return loadCSVFixture(path, data)
+	}
+	return nil, fmt.Errorf("%w: %s: unsupported extension, expected .json, .jsonl, .ndjson, .yaml, .yml or .csv", ErrFixture, path)
+}
+
+func fixtureError(path string, line int, err error) error {
+	return fmt.Errorf("%w: %s:%d: %w", ErrFixture, path, line, err)
+}
+
+func loadJSONFixture(path string, data []byte) ([]map[string]types.AttributeValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	start := skipJSONSpace(data, 0)
+	if start < len(data) && data[start] == '{' {
+		var object map[string]any
+		if err := dec.Decode(&object); err != nil {
+			return nil, fixtureError(path, jsonErrorLine(data, err, start), err)
+		}
+		item, err := fixtureItem(object)
+		if err != nil {
+			return nil, fixtureError(path, lineAt(data, start), err)
+		}
+		return []map[string]types.AttributeValue{item}, nil
+	}
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, fixtureError(path, lineAt(data, start), errors.New("expected an array of items or a single item"))
+	}
+	var items []map[string]types.AttributeValue
+	for dec.More() {
+		offset := skipJSONSpace(data, int(dec.InputOffset()))
+		var object map[string]any
+		if err := dec.Decode(&object); err != nil {
+			return nil, fixtureError(path, jsonErrorLine(data, err, offset), err)
+		}
+		item, err := fixtureItem(object)
+		if err != nil {
+			return nil, fixtureError(path, lineAt(data, offset), err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func loadJSONLFixture(path string, data []byte) ([]map[string]types.AttributeValue, error) {
+	return parseJSONLines(path, data, fixtureItem)
+}
+
+// parseJSONLines parses a JSON object per line, converting each into an item.
+func parseJSONLines(path string, data []byte, convert func(map[string]any) (map[string]types.AttributeValue, error)) ([]map[string]types.AttributeValue, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+
+	var items []map[string]types.AttributeValue
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.UseNumber()
+		var object map[string]any
+		if err := dec.Decode(&object); err != nil {
+			return nil, fixtureError(path, line, err)
+		}
+		item, err := convert(object)
+		if err != nil {
+			return nil, fixtureError(path, line, err)
+		}
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrFixture, path, err)
+	}
+	return items, nil
+}
+
+func loadYAMLFixture(path string, data []byte) ([]map[string]types.AttributeValue, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	var items []map[string]types.AttributeValue
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			return items, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrFixture, path, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+
+		nodes := []*yaml.Node{doc.Content[0]}
+		if doc.Content[0].Kind == yaml.SequenceNode {
+			nodes = doc.Content[0].Content
+		}
+		for _, node := range nodes {
+			if node.Kind != yaml.MappingNode {
+				return nil, fixtureError(path, node.Line, errors.New("expected a mapping of attributes"))
+			}
+			var object map[string]any
+			if err := node.Decode(&object); err != nil {
+				return nil, fixtureError(path, node.Line, err)
+			}
+			item, err := fixtureItem(object)
+			if err != nil {
+				return nil, fixtureError(path, node.Line, err)
+			}
+			items = append(items, item)
+		}
+	}
+}
+
+func loadCSVFixture(path string, data []byte) ([]map[string]types.AttributeValue, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+
+	names, err := r.Read()
+	if err != nil {
+		return nil, csvError(path, err)
+	}
+	names[0] = strings.TrimPrefix(names[0], "\ufeff")
+	attrTypes, err := r.Read()
+	if err != nil {
+		return nil, csvError(path, err)
+	}
+	for i, attrType := range attrTypes {
+		if _, ok := avTypes[attrType]; !ok {
+			line, _ := r.FieldPos(i)
+			return nil, fixtureError(path, line, fmt.Errorf("column '%s' has unknown type '%s'", names[i], attrType))
+		}
+	}
+
+	var items []map[string]types.AttributeValue
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, csvError(path, err)
+		}
+		line, _ := r.FieldPos(0)
+
+		item := make(map[string]types.AttributeValue, len(record))
+		for i, cell := range record {
+			if cell == "" {
+				continue
+			}
+			av, err := csvValue(attrTypes[i], cell)
+			if err != nil {
+				return nil, fixtureError(path, line, fmt.Errorf("column '%s': %w", names[i], err))
+			}
+			item[names[i]] = av
+		}
+		items = append(items, item)
+	}
+}
+
+func csvError(path string, err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fixtureError(path, parseErr.Line, parseErr.Err)
+	}
+	if err == io.EOF {
+		err = errors.New("expected a row of attribute names and a row of attribute types")
+	}
+	return fmt.Errorf("%w: %s: %w", ErrFixture, path, err)
+}
+
+// csvValue parses a cell of the given type. Binary values are base64, and
+// sets, lists and maps are JSON.
+func csvValue(attrType, cell string) (types.AttributeValue, error) {
+	switch attrType {
+	case "S", "N", "B":
+		return typedValue(map[string]any{attrType: cell})
+	case "BOOL", "NULL":
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return nil, err
+		}
+		return typedValue(map[string]any{attrType: b})
+	}
+
+	dec := json.NewDecoder(strings.NewReader(cell))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if attrType == "L" || attrType == "M" {
+		return attributevalue.Marshal(plainValue(value))
+	}
+	return typedValue(map[string]any{attrType: value})
+}
+
+// lineAt returns the line of the byte at offset.
+func lineAt(data []byte, offset int) int {
+	return bytes.Count(data[:min(offset, len(data))], []byte("\n")) + 1
+}
+
+// skipJSONSpace returns the offset of the next value, skipping the separators
+// a json.Decoder has not consumed yet.
+func skipJSONSpace(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func jsonErrorLine(data []byte, err error, fallback int) int {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return lineAt(data, int(syntaxErr.Offset))
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return lineAt(data, int(typeErr.Offset))
+	}
+	return lineAt(data, fallback)
+}
+
+// avTypes are the type descriptors of DynamoDB JSON.
+var avTypes = map[string]struct{}{
+	"S": {}, "N": {}, "B": {}, "BOOL": {}, "NULL": {}, "SS": {}, "NS": {}, "BS": {}, "L": {}, "M": {},
+}
+
+// fixtureItem converts a decoded object, reading it as DynamoDB JSON when
+// every attribute is written in that form.
+func fixtureItem(object map[string]any) (map[string]types.AttributeValue, error) {
+	if object == nil {
+		return nil, errors.New("expected an object of attributes")
+	}
+	if !isTypedItem(object) {
+		return attributevalue.MarshalMap(plainValue(object))
+	}
+	return typedItem(object)
+}
+
+// typedItem converts a decoded DynamoDB JSON item.
+func typedItem(object map[string]any) (map[string]types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, len(object))
+	for _, name := range sortedKeys(object) {
+		av, err := typedValue(object[name])
+		if err != nil {
+			return nil, fmt.Errorf("attribute '%s': %w", name, err)
+		}
+		item[name] = av
+	}
+	return item, nil
+}
+
+func isTypedItem(object map[string]any) bool {
+	if len(object) == 0 {
+		return false
+	}
+	for _, v := range object {
+		if _, ok := typedPair(v); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// typedPair returns the type descriptor of a DynamoDB JSON value.
+func typedPair(v any) (string, bool) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return "", false
+	}
+	for k := range m {
+		_, ok := avTypes[k]
+		return k, ok
+	}
+	return "", false
+}
+
+// typedValue converts a decoded DynamoDB JSON value such as {"N": "1"}.
+func typedValue(v any) (types.AttributeValue, error) {
+	attrType, ok := typedPair(v)
+	if !ok {
+		return nil, fmt.Errorf("expected a single key object naming a DynamoDB type, got %v", v)
+	}
+	value := v.(map[string]any)[attrType]
+
+	switch attrType {
+	case "S":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("S value %v is not a string", value)
+		}
+		return &types.AttributeValueMemberS{Value: s}, nil
+	case "N":
+		n, err := numberValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberN{Value: n}, nil
+	case "B":
+		b, err := binaryValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberB{Value: b}, nil
+	case "BOOL":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("BOOL value %v is not a boolean", value)
+		}
+		return &types.AttributeValueMemberBOOL{Value: b}, nil
+	case "NULL":
+		b, ok := value.(bool)
+		if !ok || !b {
+			return nil, fmt.Errorf("NULL value %v must be true", value)
+		}
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case "M":
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("M value %v is not an object", value)
+		}
+		out := make(map[string]types.AttributeValue, len(m))
+		for _, k := range sortedKeys(m) {
+			av, err := typedValue(m[k])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			out[k] = av
+		}
+		return &types.AttributeValueMemberM{Value: out}, nil
+	}
+
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s value %v is not an array", attrType, value)
+	}
+	switch attrType {
+	case "L":
+		out := make([]types.AttributeValue, len(list))
+		for i, e := range list {
+			av, err := typedValue(e)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out[i] = av
+		}
+		return &types.AttributeValueMemberL{Value: out}, nil
+	case "SS":
+		out := make([]string, len(list))
+		for i, e := range list {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("SS element %v is not a string", e)
+			}
+			out[i] = s
+		}
+		return &types.AttributeValueMemberSS{Value: out}, nil
+	case "NS":
+		out := make([]string, len(list))
+		for i, e := range list {
+			n, err := numberValue(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = n
+		}
+		return &types.AttributeValueMemberNS{Value: out}, nil
+	default: // BS
+		out := make([][]byte, len(list))
+		for i, e := range list {
+			b, err := binaryValue(e)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = b
+		}
+		return &types.AttributeValueMemberBS{Value: out}, nil
+	}
+}
+
+// numberValue accepts numbers written as strings, as DynamoDB JSON does, and
+// as numbers, as YAML and CSV cells are decoded.
+func numberValue(v any) (string, error) {
+	var s string
+	switch n := v.(type) {
+	case string:
+		s = n
+	case json.Number:
+		s = n.String()
+	case int:
+		s = strconv.Itoa(n)
+	case uint64:
+		s = strconv.FormatUint(n, 10)
+	case float64:
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	default:
+		return "", fmt.Errorf("N value %v is not a number", v)
+	}
+	if _, ok := new(big.Rat).SetString(s); !ok {
+		return "", fmt.Errorf("N value '%s' is not a number", s)
+	}
+	return s, nil
+}
+
+func binaryValue(v any) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("binary value %v is not a base64 string", v)
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("binary value '%s' is not base64: %w", s, err)
+	}
+	return b, nil
+}
+
+// plainValue prepares a decoded value for attributevalue, keeping numbers
+// decoded as json.Number exact instead of marshalling them as strings.
+func plainValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		return attributevalue.Number(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = plainValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = plainValue(e)
+		}
+		return out
+	}
+	return v
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
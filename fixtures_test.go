@@ -0,0 +1,179 @@
+package dynamotest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/rozen03/dynamotest"
+)
+
+// avCmpOpts ignores the unexported fields the SDK adds to attribute values.
+var avCmpOpts = cmpopts.IgnoreUnexported(
+	types.AttributeValueMemberS{},
+	types.AttributeValueMemberN{},
+	types.AttributeValueMemberB{},
+	types.AttributeValueMemberBOOL{},
+	types.AttributeValueMemberNULL{},
+	types.AttributeValueMemberSS{},
+	types.AttributeValueMemberNS{},
+	types.AttributeValueMemberBS{},
+	types.AttributeValueMemberL{},
+	types.AttributeValueMemberM{},
+)
+
+func TestLoadFixtures(t *testing.T) {
+	t.Parallel()
+
+	users := []map[string]types.AttributeValue{
+		{
+			"id":   &types.AttributeValueMemberS{Value: "1"},
+			"name": &types.AttributeValueMemberS{Value: "Ada"},
+			"age":  &types.AttributeValueMemberN{Value: "36"},
+			"tags": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "admin"},
+				&types.AttributeValueMemberS{Value: "dev"},
+			}},
+		},
+		{
+			"id":   &types.AttributeValueMemberS{Value: "2"},
+			"name": &types.AttributeValueMemberS{Value: "Linus"},
+			"age":  &types.AttributeValueMemberN{Value: "54"},
+			"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"city": &types.AttributeValueMemberS{Value: "Portland"},
+			}},
+		},
+	}
+
+	cases := map[string]struct {
+		path string
+		want []map[string]types.AttributeValue
+	}{
+		"json":       {path: "testdata/fixtures/users.json", want: users},
+		"typed json": {path: "testdata/fixtures/users.typed.json", want: users},
+		"jsonl":      {path: "testdata/fixtures/users.jsonl", want: users},
+		"yaml":       {path: "testdata/fixtures/users.yaml", want: users},
+		"csv":        {path: "testdata/fixtures/users.csv", want: users},
+		"every typed attribute": {
+			path: "testdata/fixtures/types.json",
+			want: []map[string]types.AttributeValue{
+				{
+					"id":      &types.AttributeValueMemberS{Value: "all"},
+					"count":   &types.AttributeValueMemberN{Value: "12345678901234567890"},
+					"blob":    &types.AttributeValueMemberB{Value: []byte("hello")},
+					"active":  &types.AttributeValueMemberBOOL{Value: true},
+					"missing": &types.AttributeValueMemberNULL{Value: true},
+					"names":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+					"scores":  &types.AttributeValueMemberNS{Value: []string{"1", "2.5"}},
+					"blobs":   &types.AttributeValueMemberBS{Value: [][]byte{[]byte("hi")}},
+					"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+						&types.AttributeValueMemberN{Value: "1"},
+						&types.AttributeValueMemberS{Value: "x"},
+					}},
+					"map": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+						"nested": &types.AttributeValueMemberBOOL{Value: false},
+					}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := dynamotest.LoadFixtures(tc.path)
+			if err != nil {
+				t.Fatalf("failed to load fixtures: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, avCmpOpts); diff != "" {
+				t.Errorf("items didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadFixtures_Errors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		path    string
+		wantErr string
+	}{
+		"jsonl syntax error": {
+			path:    "testdata/fixtures/broken.jsonl",
+			wantErr: "testdata/fixtures/broken.jsonl:3: invalid character '}'",
+		},
+		"typed json bad number": {
+			path:    "testdata/fixtures/broken.typed.json",
+			wantErr: "testdata/fixtures/broken.typed.json:3: attribute 'age': N value 'fifty' is not a number",
+		},
+		"csv bad boolean": {
+			path:    "testdata/fixtures/broken.csv",
+			wantErr: "testdata/fixtures/broken.csv:4: column 'active': strconv.ParseBool",
+		},
+		"unsupported extension": {
+			path:    "testdata/terraform/config/main.tf",
+			wantErr: "unsupported extension",
+		},
+		"missing file": {
+			path:    "testdata/fixtures/missing.json",
+			wantErr: "no such file",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := dynamotest.LoadFixtures(tc.path)
+			if !errors.Is(err, dynamotest.ErrFixture) {
+				t.Fatalf("expected ErrFixture, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error to contain '%s', got '%v'", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreateTestingTableFromFixtures(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	table := client.CreateTestingTableFromFixtures(t, "fixtures", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	}, "testdata/fixtures/users.json", "testdata/fixtures/types.json")
+
+	out, err := client.Scan(context.Background(), &dynamodb.ScanInput{
+		TableName: aws.String(table),
+		Select:    types.SelectCount,
+	})
+	if err != nil {
+		t.Fatalf("failed to scan table: %v", err)
+	}
+	if out.Count != 3 {
+		t.Errorf("expected 3 items, got %d", out.Count)
+	}
+}
@@ -0,0 +1,120 @@
+package dynamotest
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// newClient connects to the endpoint with a client whose requests go through
+// the fault injector, the recorder and, when enabled, the capacity accountant
+// of the returned Client.
+func newClient(ctx context.Context, containerID, endpoint string, o *options) (Client, error) {
+	c := Client{
+		ContainerID: containerID,
+		Endpoint:    endpoint,
+		Faults:      &FaultInjector{},
+		Recorder:    &Recorder{},
+		Capacity:    &CapacityAccountant{enabled: o.capacity},
+	}
+
+	middlewares := []func(*middleware.Stack) error{addInputMiddleware, c.Recorder.addMiddleware}
+	if c.Capacity.enabled {
+		middlewares = append(middlewares, c.Capacity.addMiddleware)
+	}
+	middlewares = append(middlewares, c.Faults.addMiddleware)
+
+	dynamoClient, err := newDynamoClient(ctx, endpoint, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, middlewares...)
+	})
+	if err != nil {
+		return Client{}, err
+	}
+	c.Client = dynamoClient
+	c.Capacity.client = dynamoClient
+	return c, nil
+}
+
+type (
+	internalKey struct{}
+	inputKey    struct{}
+)
+
+// internal marks the requests the harness makes itself, to create, seed,
+// dump or delete tables, which faults, the recorder and the capacity
+// accountant leave alone.
+func internal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalKey{}, true)
+}
+
+func isInternal(ctx context.Context) bool {
+	v, _ := ctx.Value(internalKey{}).(bool)
+	return v
+}
+
+// addInputMiddleware keeps the input of the operation in the context, as the
+// later steps of the stack only see the HTTP request.
+func addInputMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("dynamotest.Input",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			return next.HandleInitialize(context.WithValue(ctx, inputKey{}, in.Parameters), in)
+		}), middleware.After)
+}
+
+func operationInput(ctx context.Context) any {
+	return ctx.Value(inputKey{})
+}
+
+// requestTables returns the tables an operation input is about.
+func requestTables(input any) []string {
+	var tables []string
+	switch in := input.(type) {
+	case *dynamodb.BatchGetItemInput:
+		for table := range in.RequestItems {
+			tables = append(tables, table)
+		}
+		// Sorted, as maps have no order.
+		slices.Sort(tables)
+	case *dynamodb.BatchWriteItemInput:
+		for table := range in.RequestItems {
+			tables = append(tables, table)
+		}
+		slices.Sort(tables)
+	case *dynamodb.TransactGetItemsInput:
+		for _, item := range in.TransactItems {
+			if item.Get != nil && item.Get.TableName != nil {
+				tables = append(tables, *item.Get.TableName)
+			}
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		for _, item := range in.TransactItems {
+			switch {
+			case item.ConditionCheck != nil && item.ConditionCheck.TableName != nil:
+				tables = append(tables, *item.ConditionCheck.TableName)
+			case item.Put != nil && item.Put.TableName != nil:
+				tables = append(tables, *item.Put.TableName)
+			case item.Delete != nil && item.Delete.TableName != nil:
+				tables = append(tables, *item.Delete.TableName)
+			case item.Update != nil && item.Update.TableName != nil:
+				tables = append(tables, *item.Update.TableName)
+			}
+		}
+	default:
+		// Every other operation on a table has a TableName.
+		v := reflect.ValueOf(input)
+		if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		field := v.Elem().FieldByName("TableName")
+		if !field.IsValid() {
+			return nil
+		}
+		if name, ok := field.Interface().(*string); ok && name != nil {
+			tables = append(tables, *name)
+		}
+	}
+	return tables
+}
@@ -1,10 +1,44 @@
 package dynamotest
 
-import "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
 
 var GlobalClient *Client
 
 type Client struct {
 	*dynamodb.Client
 	ContainerID string
+
+	// Endpoint is the URL of the DynamoDB instance, e.g. http://localhost:49153,
+	// which other tools such as the AWS CLI can use to reach the same instance.
+	Endpoint string
+
+	// KeepTables decides whether CreateTestingTable deletes its tables once
+	// the test completes.
+	KeepTables KeepTables
+
+	// SeedConcurrency is the number of chunks of initial data written at the
+	// same time. Values below 1 write the chunks one after another.
+	SeedConcurrency int
+
+	// TableActiveTimeout is how long CreateTestingTable waits for the table
+	// and its indexes to become ACTIVE. Zero means one minute.
+	TableActiveTimeout time.Duration
+
+	// Faults fails or delays the requests of Client, see FaultInjector.
+	Faults *FaultInjector
+
+	// Recorder records the requests of Client, see Recorder.
+	Recorder *Recorder
+
+	// Capacity estimates the capacity units the requests of Client consume
+	// when created with WithCapacityAccounting, see CapacityAccountant.
+	Capacity *CapacityAccountant
+
+	// leased tracks the tables created through a Client leased from a Pool
+	// of shared instances, nil otherwise.
+	leased *leasedTables
 }
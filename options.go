@@ -0,0 +1,206 @@
+package dynamotest
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// Option configures the DynamoDB Local instance started by NewDynamoDBWithOptions.
+type Option func(*options)
+
+type options struct {
+	repository    string
+	tag           string
+	containerName string
+	env           []string
+	jvmFlags      []string
+	flags         []string
+	memoryLimit   int64
+	labels        map[string]string
+	hostPort      int
+	reuse         string
+	endpoint      string
+	readyTimeout  time.Duration
+	readyInterval time.Duration
+	dockerPolicy  *DockerPolicy
+	capacity      bool
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		repository:    dynamoDBLocalRepo,
+		tag:           dynamoDBLocalTag,
+		readyTimeout:  readyTimeout,
+		readyInterval: readyInterval,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRepository sets the image repository, e.g. a private registry mirror of
+// amazon/dynamodb-local.
+func WithRepository(repository string) Option {
+	return func(o *options) { o.repository = repository }
+}
+
+// WithTag pins the DynamoDB Local image tag instead of using latest.
+func WithTag(tag string) Option {
+	return func(o *options) { o.tag = tag }
+}
+
+// WithContainerName sets the name of the container. Docker rejects duplicate
+// names, so this is only useful when a single instance is started at a time.
+func WithContainerName(name string) Option {
+	return func(o *options) { o.containerName = name }
+}
+
+// WithEnv adds environment variables in the KEY=value form.
+func WithEnv(env ...string) Option {
+	return func(o *options) { o.env = append(o.env, env...) }
+}
+
+// WithJVMFlags adds flags passed to the JVM before the DynamoDB Local jar,
+// such as -Xmx512m.
+func WithJVMFlags(flags ...string) Option {
+	return func(o *options) { o.jvmFlags = append(o.jvmFlags, flags...) }
+}
+
+// WithFlags adds DynamoDB Local command-line flags such as -sharedDb,
+// -inMemory or -delayTransientStatuses. Setting any flag replaces the image
+// default of -inMemory, so include it again if it is still wanted.
+func WithFlags(flags ...string) Option {
+	return func(o *options) { o.flags = append(o.flags, flags...) }
+}
+
+// WithMemoryLimit sets the container memory limit in bytes.
+func WithMemoryLimit(bytes int64) Option {
+	return func(o *options) { o.memoryLimit = bytes }
+}
+
+// WithLabels adds labels to the container.
+func WithLabels(labels map[string]string) Option {
+	return func(o *options) {
+		if o.labels == nil {
+			o.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			o.labels[k] = v
+		}
+	}
+}
+
+// WithHostPort binds DynamoDB Local to a fixed host port instead of a random
+// free one. Only one container can use a given port at a time.
+func WithHostPort(port int) Option {
+	return func(o *options) { o.hostPort = port }
+}
+
+// WithEndpoint connects to an already running DynamoDB compatible endpoint,
+// such as a DynamoDB Local service container in CI or LocalStack, instead of
+// starting a container. Docker is not needed then, and the purge function
+// leaves the endpoint untouched. The DYNAMOTEST_ENDPOINT environment variable
+// does the same without changing code.
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) { o.endpoint = endpoint }
+}
+
+func (o *options) externalEndpoint() string {
+	if o.endpoint != "" {
+		return o.endpoint
+	}
+	return os.Getenv(endpointEnv)
+}
+
+// WithReadyTimeout sets how long to wait for DynamoDB to answer requests
+// before giving up with ErrEndpointNotReady. It defaults to one minute.
+func WithReadyTimeout(timeout time.Duration) Option {
+	return func(o *options) { o.readyTimeout = timeout }
+}
+
+// WithReadyInterval sets how often DynamoDB is probed while waiting for it to
+// answer requests.
+func WithReadyInterval(interval time.Duration) Option {
+	return func(o *options) { o.readyInterval = interval }
+}
+
+// DockerPolicy decides what New does when Docker is unavailable.
+type DockerPolicy int
+
+const (
+	// SkipWithoutDocker skips the test. This is the default.
+	SkipWithoutDocker DockerPolicy = iota
+	// FailWithoutDocker fails the test.
+	FailWithoutDocker
+)
+
+// WithDockerPolicy sets what New does when Docker is unavailable, taking
+// precedence over the DYNAMOTEST_REQUIRE_DOCKER environment variable.
+func WithDockerPolicy(policy DockerPolicy) Option {
+	return func(o *options) { o.dockerPolicy = &policy }
+}
+
+func (o *options) dockerPolicyFor() DockerPolicy {
+	if o.dockerPolicy != nil {
+		return *o.dockerPolicy
+	}
+	switch strings.ToLower(os.Getenv(requireDockerEnv)) {
+	case "1", "true":
+		return FailWithoutDocker
+	}
+	return SkipWithoutDocker
+}
+
+// runOptions builds the dockertest options for the configured container.
+func (o *options) runOptions() *dockertest.RunOptions {
+	runOpt := &dockertest.RunOptions{
+		Repository: o.repository,
+		Tag:        o.tag,
+		Name:       o.containerName,
+		Env:        o.env,
+		Labels:     o.labels,
+
+		ExposedPorts: []string{dynamoDBLocalPort},
+		PortBindings: map[docker.Port][]docker.PortBinding{
+			// An empty host port lets Docker pick a free one.
+			dynamoDBLocalPort: {{HostPort: o.hostPortBinding()}},
+		},
+	}
+
+	// The image runs "java" as its entrypoint with "-jar DynamoDBLocal.jar
+	// -inMemory" as the default command, so the command is only rebuilt when
+	// there is something to change.
+	if len(o.jvmFlags) > 0 || len(o.flags) > 0 {
+		cmd := append([]string{}, o.jvmFlags...)
+		cmd = append(cmd, "-jar", "DynamoDBLocal.jar")
+		if len(o.flags) > 0 {
+			cmd = append(cmd, o.flags...)
+		} else {
+			cmd = append(cmd, "-inMemory")
+		}
+		runOpt.Cmd = cmd
+	}
+
+	return runOpt
+}
+
+func (o *options) hostPortBinding() string {
+	if o.hostPort <= 0 {
+		return ""
+	}
+	return strconv.Itoa(o.hostPort)
+}
+
+// hostConfig applies the settings that dockertest only exposes through the
+// Docker host configuration.
+func (o *options) hostConfig(hc *docker.HostConfig) {
+	if o.memoryLimit > 0 {
+		hc.Memory = o.memoryLimit
+	}
+}
@@ -0,0 +1,253 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Pool hands out DynamoDB Local instances to parallel tests, so a noisy test
+// does not slow down the others while the containers are still started only
+// once. Instances are reset by deleting all their tables when a test releases
+// them, and the pool grows on demand up to its maximum size.
+//
+// With WithEndpoint or WithReuse, every instance is the same shared DynamoDB,
+// whose other tables may belong to other tests or test binaries, so only the
+// tables the test created with CreateTestingTable are deleted instead.
+type Pool struct {
+	opts   []Option
+	max    int
+	shared bool
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	idle    []Client
+	purges  map[*dynamodb.Client]func() error
+	started int
+	closed  bool
+}
+
+// NewPool starts size instances configured with opts, and lets the pool grow
+// up to max instances. Close must be called to purge them, usually at the end
+// of TestMain.
+func NewPool(ctx context.Context, size, max int, opts ...Option) (*Pool, error) {
+	if size < 0 || max < 1 || size > max {
+		return nil, fmt.Errorf("dynamotest: invalid pool size %d and max %d", size, max)
+	}
+
+	o := newOptions(opts...)
+	p := &Pool{
+		opts:   opts,
+		max:    max,
+		shared: o.externalEndpoint() != "" || o.reuseName() != "",
+		purges: make(map[*dynamodb.Client]func() error, max),
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, size)
+	)
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := p.start(ctx)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			p.mu.Lock()
+			p.idle = append(p.idle, c)
+			p.started++
+			p.mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, errors.Join(err, p.Close())
+	}
+	return p, nil
+}
+
+// Acquire leases an instance to the test until it completes, waiting for one
+// to be released when the pool is at its maximum size.
+func (p *Pool) Acquire(t *testing.T) Client {
+	t.Helper()
+
+	c, err := p.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Could not acquire DynamoDB from pool: %v", err)
+	}
+	if p.shared {
+		c.leased = &leasedTables{}
+	}
+	t.Logf("Using DynamoDB container %s at %s", c.ContainerID, c.Endpoint)
+
+	t.Cleanup(func() {
+		if err := p.release(context.Background(), c); err != nil {
+			t.Logf("Could not reset DynamoDB container %s, it has been replaced: %v", c.ContainerID, err)
+		}
+	})
+
+	return c
+}
+
+// Close purges every instance that is not leased. Instances still leased are
+// purged when released.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.started -= len(idle)
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	var errs []error
+	for _, c := range idle {
+		errs = append(errs, p.purge(c))
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Pool) acquire(ctx context.Context) (Client, error) {
+	p.mu.Lock()
+	for {
+		switch {
+		case p.closed:
+			p.mu.Unlock()
+			return Client{}, errors.New("dynamotest: pool is closed")
+
+		case len(p.idle) > 0:
+			c := p.idle[len(p.idle)-1]
+			p.idle = p.idle[:len(p.idle)-1]
+			p.mu.Unlock()
+			return c, nil
+
+		case p.started < p.max:
+			p.started++
+			p.mu.Unlock()
+
+			c, err := p.start(ctx)
+			if err != nil {
+				p.mu.Lock()
+				p.started--
+				p.cond.Signal()
+				p.mu.Unlock()
+				return Client{}, err
+			}
+			return c, nil
+		}
+		p.cond.Wait()
+	}
+}
+
+// release resets the instance and returns it to the pool. Instances that
+// cannot be reset are purged, making room for a new one.
+func (p *Pool) release(ctx context.Context, c Client) error {
+	var err error
+	if p.shared {
+		err = c.deleteLeasedTables(ctx)
+	} else {
+		err = c.deleteAllTables(ctx)
+	}
+	c.Faults.Reset()
+	c.Recorder.Reset()
+	c.Capacity.Reset()
+
+	p.mu.Lock()
+	if err == nil && !p.closed {
+		p.idle = append(p.idle, c)
+		p.cond.Signal()
+		p.mu.Unlock()
+		return nil
+	}
+	p.started--
+	p.cond.Signal()
+	p.mu.Unlock()
+
+	return errors.Join(err, p.purge(c))
+}
+
+func (p *Pool) start(ctx context.Context) (Client, error) {
+	c, purgeE, err := NewDynamoDBE(ctx, p.opts...)
+	if err != nil {
+		return Client{}, err
+	}
+	p.mu.Lock()
+	p.purges[c.Client] = purgeE
+	p.mu.Unlock()
+	return c, nil
+}
+
+func (p *Pool) purge(c Client) error {
+	p.mu.Lock()
+	purgeE := p.purges[c.Client]
+	delete(p.purges, c.Client)
+	p.mu.Unlock()
+
+	if purgeE == nil {
+		return nil
+	}
+	return purgeE()
+}
+
+// deleteAllTables deletes every table of the instance.
+func (c Client) deleteAllTables(ctx context.Context) error {
+	paginator := dynamodb.NewListTablesPaginator(c.Client, &dynamodb.ListTablesInput{})
+	var tables []string
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(internal(ctx))
+		if err != nil {
+			return err
+		}
+		tables = append(tables, out.TableNames...)
+	}
+
+	for _, table := range tables {
+		if err := c.deleteTable(ctx, table); err != nil {
+			return fmt.Errorf("could not delete table '%s': %w", table, err)
+		}
+	}
+	return nil
+}
+
+// leasedTables are the tables created through a Client leased from a pool of
+// shared instances.
+type leasedTables struct {
+	mu     sync.Mutex
+	tables []string
+}
+
+func (l *leasedTables) add(table string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tables = append(l.tables, table)
+}
+
+// deleteLeasedTables deletes the tables created through the leased Client,
+// which the tests usually deleted already.
+func (c Client) deleteLeasedTables(ctx context.Context) error {
+	c.leased.mu.Lock()
+	tables := c.leased.tables
+	c.leased.tables = nil
+	c.leased.mu.Unlock()
+
+	for _, table := range tables {
+		var notFound *types.ResourceNotFoundException
+		if err := c.deleteTable(ctx, table); err != nil && !errors.As(err, &notFound) {
+			return fmt.Errorf("could not delete table '%s': %w", table, err)
+		}
+	}
+	return nil
+}
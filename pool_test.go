@@ -0,0 +1,141 @@
+package dynamotest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestPool_ResetsInstanceOnRelease(t *testing.T) {
+	t.Parallel()
+
+	pool, err := dynamotest.NewPool(context.Background(), 1, 1)
+	if errors.Is(err, dynamotest.ErrDockerUnavailable) {
+		t.Skipf("Skipping test as Docker is unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := pool.Close(); err != nil {
+			t.Errorf("failed to close pool: %v", err)
+		}
+	})
+
+	var containerID string
+	t.Run("first lease", func(t *testing.T) {
+		client := pool.Acquire(t)
+		client.KeepTables = dynamotest.KeepTablesAlways
+		containerID = client.ContainerID
+
+		table := client.CreateTestingTable(t, "pool", dynamodb.CreateTableInput{
+			AttributeDefinitions: []types.AttributeDefinition{
+				{
+					AttributeName: aws.String("id"),
+					AttributeType: types.ScalarAttributeTypeS,
+				},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{
+					AttributeName: aws.String("id"),
+					KeyType:       types.KeyTypeHash,
+				},
+			},
+		})
+
+		client.Faults.Inject(t, dynamotest.Fault{Operation: "GetItem", Latency: time.Millisecond})
+		_, err := client.GetItem(context.Background(), &dynamodb.GetItemInput{
+			TableName: aws.String(table),
+			Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+		})
+		if err != nil {
+			t.Fatalf("failed to get item: %v", err)
+		}
+	})
+
+	client := pool.Acquire(t)
+	if client.ContainerID != containerID {
+		t.Errorf("expected container '%s' to be reused, got '%s'", containerID, client.ContainerID)
+	}
+	if calls := client.Recorder.Calls(""); len(calls) != 0 {
+		t.Errorf("expected no calls after release, got %v", calls)
+	}
+	if rcu := client.Capacity.RCU(); rcu != 0 {
+		t.Errorf("expected no consumption after release, got %g RCU", rcu)
+	}
+	if got := client.Faults.Injected("GetItem"); got != 0 {
+		t.Errorf("expected no injected faults after release, got %d", got)
+	}
+
+	out, err := client.ListTables(context.Background(), &dynamodb.ListTablesInput{})
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	if len(out.TableNames) != 0 {
+		t.Errorf("expected no tables after release, got %v", out.TableNames)
+	}
+}
+
+func TestPool_SharedInstanceKeepsOtherTables(t *testing.T) {
+	t.Parallel()
+
+	shared := dynamotest.New(t)
+
+	schema := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	}
+	other := shared.CreateTestingTable(t, "other", schema)
+
+	pool, err := dynamotest.NewPool(context.Background(), 1, 1, dynamotest.WithEndpoint(shared.Endpoint))
+	if errors.Is(err, dynamotest.ErrDockerUnavailable) {
+		t.Skipf("Skipping test as Docker is unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := pool.Close(); err != nil {
+			t.Errorf("failed to close pool: %v", err)
+		}
+	})
+
+	var leased string
+	t.Run("lease", func(t *testing.T) {
+		client := pool.Acquire(t)
+		client.KeepTables = dynamotest.KeepTablesAlways
+		leased = client.CreateTestingTable(t, "pool", schema)
+	})
+
+	out, err := shared.ListTables(context.Background(), &dynamodb.ListTablesInput{})
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	tables := map[string]bool{}
+	for _, table := range out.TableNames {
+		tables[table] = true
+	}
+	if !tables[other] {
+		t.Errorf("expected table '%s' of another test to be kept, got %v", other, out.TableNames)
+	}
+	if tables[leased] {
+		t.Errorf("expected leased table '%s' to be deleted, got %v", leased, out.TableNames)
+	}
+}
@@ -0,0 +1,137 @@
+package dynamotest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Call is a request made through a Client.
+type Call struct {
+	// Operation is the name of the operation, e.g. "Query".
+	Operation string
+
+	// Tables are the tables of the request, several for batch and transaction
+	// requests.
+	Tables []string
+
+	// Input and Output are the input and output of the operation, e.g.
+	// *dynamodb.QueryInput and *dynamodb.QueryOutput. Output is nil when the
+	// request failed with Err.
+	Input  any
+	Output any
+	Err    error
+}
+
+func (c Call) String() string {
+	s := c.Operation
+	if len(c.Tables) > 0 {
+		s += " on " + strings.Join(c.Tables, ", ")
+	}
+	if c.Err != nil {
+		s += fmt.Sprintf(" failed: %v", c.Err)
+	}
+	return s
+}
+
+// Recorder records the requests made through a Client, to assert how the code
+// under test uses DynamoDB. A call is recorded once, however many times the
+// SDK retried it, and the harness' own requests, such as creating or seeding
+// tables, are left out.
+//
+// Calls are recorded until Reset, so tests sharing a Client should reset it
+// first and not run in parallel.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the recorded calls of the operation in the order they were
+// made, or every call when it is empty.
+func (r *Recorder) Calls(operation string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, c := range r.calls {
+		if operation == "" || c.Operation == operation {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets every recorded call.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
+
+// AssertNoScans fails the test if a Scan has been recorded.
+func (r *Recorder) AssertNoScans(t *testing.T) {
+	t.Helper()
+
+	if scans := r.Calls("Scan"); len(scans) > 0 {
+		t.Errorf("expected no Scan, got %d:\n%s", len(scans), formatCalls(scans))
+	}
+}
+
+// AssertCalled fails the test unless a call of the operation matching the
+// matcher has been recorded. A nil matcher matches every call.
+func (r *Recorder) AssertCalled(t *testing.T, operation string, matcher func(Call) bool) {
+	t.Helper()
+
+	calls := r.Calls(operation)
+	for _, c := range calls {
+		if matcher == nil || matcher(c) {
+			return
+		}
+	}
+	if len(calls) == 0 {
+		t.Errorf("expected a call of %s, got none", operation)
+		return
+	}
+	t.Errorf("expected a matching call of %s, got %d not matching:\n%s", operation, len(calls), formatCalls(calls))
+}
+
+func formatCalls(calls []Call) string {
+	lines := make([]string, len(calls))
+	for i, c := range calls {
+		lines[i] = fmt.Sprintf("\t%s: %+v", c, c.Input)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addMiddleware records the calls before the retry middleware, so that every
+// call is recorded once.
+func (r *Recorder) addMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("dynamotest.Recorder",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if isInternal(ctx) {
+				return out, metadata, err
+			}
+
+			call := Call{
+				Operation: awsmiddleware.GetOperationName(ctx),
+				Tables:    requestTables(in.Parameters),
+				Input:     in.Parameters,
+				Err:       err,
+			}
+			if err == nil {
+				call.Output = out.Result
+			}
+
+			r.mu.Lock()
+			r.calls = append(r.calls, call)
+			r.mu.Unlock()
+			return out, metadata, err
+		}), middleware.After)
+}
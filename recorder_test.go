@@ -0,0 +1,124 @@
+package dynamotest_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	table := client.CreateTestingTable(t, "recorder", faultSchema, map[string]string{"id": "1"})
+	ctx := context.Background()
+
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "2"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to put item: %v", err)
+	}
+	_, err = client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(table),
+		Key:                       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "1"}},
+		UpdateExpression:          aws.String("SET #n = :n"),
+		ExpressionAttributeNames:  map[string]string{"#n": "name"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":n": &types.AttributeValueMemberS{Value: "one"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to update item: %v", err)
+	}
+	_, err = client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "3"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to get item: %v", err)
+	}
+
+	// The requests of the harness are not recorded.
+	dynamotest.AssertItemCount(t, client, table, 2)
+
+	var operations []string
+	for _, call := range client.Recorder.Calls("") {
+		operations = append(operations, call.Operation)
+		if diff := cmp.Diff([]string{table}, call.Tables); diff != "" {
+			t.Errorf("%s tables didn't match (-want / +got)\n%s", call.Operation, diff)
+		}
+	}
+	if diff := cmp.Diff([]string{"PutItem", "UpdateItem", "GetItem"}, operations); diff != "" {
+		t.Errorf("operations didn't match (-want / +got)\n%s", diff)
+	}
+
+	gets := client.Recorder.Calls("GetItem")
+	if len(gets) != 1 {
+		t.Fatalf("expected 1 GetItem, got %d", len(gets))
+	}
+	if out, ok := gets[0].Output.(*dynamodb.GetItemOutput); !ok || out.Item != nil {
+		t.Errorf("expected GetItem output without item, got %+v", gets[0].Output)
+	}
+
+	client.Recorder.AssertNoScans(t)
+	client.Recorder.AssertCalled(t, "UpdateItem", func(c dynamotest.Call) bool {
+		return aws.ToString(c.Input.(*dynamodb.UpdateItemInput).UpdateExpression) == "SET #n = :n"
+	})
+	client.Recorder.AssertCalled(t, "PutItem", nil)
+
+	_, err = client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String(table)})
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	if got := len(client.Recorder.Calls("Scan")); got != 1 {
+		t.Errorf("expected 1 Scan, got %d", got)
+	}
+
+	client.Recorder.Reset()
+	if got := client.Recorder.Calls(""); len(got) != 0 {
+		t.Errorf("expected no calls after Reset, got %v", got)
+	}
+}
+
+func TestRecorder_BatchTables(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	first := client.CreateTestingTable(t, "recorder", faultSchema)
+	second := client.CreateTestingTable(t, "recorder", faultSchema)
+	put := func(id string) types.WriteRequest {
+		return types.WriteRequest{PutRequest: &types.PutRequest{
+			Item: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+		}}
+	}
+
+	// Batches are keyed by table, so the tables must not come in map order.
+	for i := 0; i < 10; i++ {
+		_, err := client.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{
+				first:  {put("1")},
+				second: {put("2")},
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to write batch: %v", err)
+		}
+	}
+
+	want := []string{first, second}
+	sort.Strings(want)
+	for _, call := range client.Recorder.Calls("BatchWriteItem") {
+		if diff := cmp.Diff(want, call.Tables); diff != "" {
+			t.Fatalf("tables didn't match (-want / +got)\n%s", diff)
+		}
+	}
+}
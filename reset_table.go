@@ -0,0 +1,89 @@
+package dynamotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ResetTable deletes every item of the table and seeds it again with
+// initialData, which is much faster than creating a new table when the schema
+// has several indexes. This lets table-driven tests share one table per
+// schema, as long as the cases do not run in parallel.
+func (c Client) ResetTable(t *testing.T, table string, initialData ...any) {
+	t.Helper()
+
+	if err := c.ResetTableE(context.Background(), table, initialData...); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Logf("Table '%s' has been reset", table)
+}
+
+// ResetTableE works like ResetTable, but returns an error instead of failing
+// the test. The error wraps one of ErrTableReset, ErrSeedMarshal or
+// ErrSeedWrite.
+func (c Client) ResetTableE(ctx context.Context, table string, initialData ...any) error {
+	items, err := marshalItems(initialData)
+	if err != nil {
+		return err
+	}
+
+	keys, err := c.scanKeys(ctx, table)
+	if err != nil {
+		return fmt.Errorf("%w '%s': %w", ErrTableReset, table, err)
+	}
+	if err := c.deleteItems(ctx, table, keys); err != nil {
+		return err
+	}
+
+	return c.writeItems(ctx, table, items)
+}
+
+// scanKeys returns the primary key of every item in the table.
+func (c Client) scanKeys(ctx context.Context, table string) ([]map[string]types.AttributeValue, error) {
+	keySchema, err := c.keySchema(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(keySchema))
+	projection := ""
+	for i, key := range keySchema {
+		placeholder := fmt.Sprintf("#k%d", i)
+		names[placeholder] = aws.ToString(key.AttributeName)
+		if projection != "" {
+			projection += ", "
+		}
+		projection += placeholder
+	}
+
+	var keys []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(c.Client, &dynamodb.ScanInput{
+		TableName:                aws.String(table),
+		ProjectionExpression:     aws.String(projection),
+		ExpressionAttributeNames: names,
+		ConsistentRead:           aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(internal(ctx))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, out.Items...)
+	}
+	return keys, nil
+}
+
+// keySchema returns the primary key of the table.
+func (c Client) keySchema(ctx context.Context, table string) ([]types.KeySchemaElement, error) {
+	out, err := c.Client.DescribeTable(internal(ctx), &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return nil, err
+	}
+	return out.Table.KeySchema, nil
+}
@@ -0,0 +1,82 @@
+package dynamotest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestResetTable(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	initialData := make([]any, 0, 60)
+	for i := 0; i < 60; i++ {
+		initialData = append(initialData, map[string]interface{}{
+			"id":   "user",
+			"date": fmt.Sprintf("2024-01-%02d", i),
+		})
+	}
+
+	table := client.CreateTestingTable(t, "reset", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("date"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("date"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	}, initialData...)
+
+	client.ResetTable(t, table,
+		map[string]interface{}{"id": "other", "date": "2024-02-01"},
+		map[string]interface{}{"id": "other", "date": "2024-02-02"},
+	)
+
+	out, err := client.Scan(context.Background(), &dynamodb.ScanInput{TableName: aws.String(table)})
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	if len(out.Items) != 2 {
+		t.Fatalf("expected 2 items after reset, got %d", len(out.Items))
+	}
+	for _, item := range out.Items {
+		if id := item["id"].(*types.AttributeValueMemberS).Value; id != "other" {
+			t.Errorf("expected only reseeded items, got id '%s'", id)
+		}
+	}
+}
+
+func TestResetTableE_SeedMarshalError(t *testing.T) {
+	t.Parallel()
+
+	// Marshalling happens before any call to DynamoDB, so no instance is needed.
+	var client dynamotest.Client
+	err := client.ResetTableE(context.Background(), "table", map[string]any{
+		"id": unmarshallableData{},
+	})
+	if !errors.Is(err, dynamotest.ErrSeedMarshal) {
+		t.Fatalf("expected ErrSeedMarshal, got %v", err)
+	}
+}
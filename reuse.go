@@ -0,0 +1,287 @@
+package dynamotest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+// WithReuse shares a single DynamoDB Local container between every test
+// binary using the same name, which saves starting a container per package
+// in `go test ./...`. The container is looked up by label and started if
+// there is no running one yet. Options that change the container only take
+// effect when it is started.
+//
+// Tests stay isolated through the unique table names of CreateTestingTable.
+// Every user holds a lease on the container, and the last one to purge it
+// removes the container. Leases of processes that died without purging
+// expire after a while, so the container is eventually removed as well.
+//
+// Setting the DYNAMOTEST_REUSE environment variable to 1 or to a name enables
+// reuse without changing code.
+func WithReuse(name string) Option {
+	return func(o *options) { o.reuse = name }
+}
+
+// reuseName returns the name of the shared container, if reuse is enabled.
+func (o *options) reuseName() string {
+	if o.reuse != "" {
+		return o.reuse
+	}
+	switch env := os.Getenv(reuseEnv); strings.ToLower(env) {
+	case "", "0", "false":
+		return ""
+	case "1", "true":
+		return defaultReuseName
+	default:
+		return env
+	}
+}
+
+// reuseDynamoDB attaches to the shared container with the given name, starting
+// it when there is none or the existing one has stopped.
+func reuseDynamoDB(ctx context.Context, pool *dockertest.Pool, o *options, name string) (Client, func() error, error) {
+	var resource *dockertest.Resource
+	l, err := attachShared(name, func() (err error) {
+		resource, err = sharedContainer(ctx, pool, o, name)
+		return err
+	})
+	if err != nil {
+		return Client{}, nil, err
+	}
+
+	endpoint := "http://" + resource.GetHostPort(dynamoDBLocalPort)
+
+	c, err := createDB(ctx, pool, resource, endpoint, o)
+	if err != nil {
+		_ = l.drop()
+		return Client{}, nil, err
+	}
+
+	purgeE := func() error {
+		return detachShared(l, func() error {
+			if err := pool.Purge(resource); err != nil {
+				return fmt.Errorf("%w: %w", ErrContainerPurge, err)
+			}
+			return nil
+		})
+	}
+
+	return c, purgeE, nil
+}
+
+// sharedContainer finds the running container labeled with the reuse name, or
+// starts it.
+func sharedContainer(ctx context.Context, pool *dockertest.Pool, o *options, name string) (*dockertest.Resource, error) {
+	// Another process may start the container between listing and starting
+	// it, in which case Docker rejects the duplicate name and we look again.
+	for attempt := 0; attempt < reuseAttempts; attempt++ {
+		containers, err := pool.Client.ListContainers(docker.ListContainersOptions{
+			All: true,
+			Filters: map[string][]string{
+				"label": {reuseLabel + "=" + name},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDockerUnavailable, err)
+		}
+
+		if len(containers) > 0 {
+			container, err := startedContainer(ctx, pool, containers[0].ID, o)
+			if err != nil {
+				return nil, err
+			}
+			// A running container may still be booting, which createDB waits
+			// for, so only a stopped or exited one is replaced.
+			if container != nil && container.State.Running {
+				return &dockertest.Resource{Container: container}, nil
+			}
+			err = pool.Client.RemoveContainer(docker.RemoveContainerOptions{ID: containers[0].ID, Force: true, RemoveVolumes: true})
+			if err != nil && !errors.As(err, new(*docker.NoSuchContainer)) {
+				return nil, fmt.Errorf("%w: could not remove stopped shared container: %w", ErrContainerStart, err)
+			}
+		}
+
+		runOpt := o.runOptions()
+		runOpt.Name = reuseContainerPrefix + name
+		runOpt.Labels = map[string]string{reuseLabel: name}
+		for k, v := range o.labels {
+			runOpt.Labels[k] = v
+		}
+
+		resource, err := pool.RunWithOptions(runOpt, o.hostConfig)
+		if errors.Is(err, docker.ErrContainerAlreadyExists) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrContainerStart, err)
+		}
+		return resource, nil
+	}
+	return nil, fmt.Errorf("%w: shared container '%s' kept changing while starting it", ErrContainerStart, name)
+}
+
+// startedContainer inspects the container, waiting while another process is
+// starting it, and returns nil when it has been removed meanwhile.
+func startedContainer(ctx context.Context, pool *dockertest.Pool, id string, o *options) (*docker.Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.readyTimeout)
+	defer cancel()
+
+	for {
+		container, err := pool.Client.InspectContainer(id)
+		if errors.As(err, new(*docker.NoSuchContainer)) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrDockerUnavailable, err)
+		}
+		if container.State.Status != "created" && !container.State.Restarting {
+			return container, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: shared container '%s' not started after %s", ErrContainerStart, container.Name, o.readyTimeout)
+		case <-time.After(o.readyInterval):
+		}
+	}
+}
+
+// attachShared takes a lease on the shared container with the given name and
+// attaches to it. Both are done under the lock of the leases, so that the
+// container is not purged by its last user meanwhile.
+func attachShared(name string, attach func() error) (lease, error) {
+	unlock, err := lockLeases(name)
+	if err != nil {
+		return lease{}, fmt.Errorf("%w: could not lock leases of shared container '%s': %w", ErrContainerStart, name, err)
+	}
+	defer unlock()
+
+	l, err := acquireLease(name)
+	if err != nil {
+		return lease{}, fmt.Errorf("%w: could not lease shared container '%s': %w", ErrContainerStart, name, err)
+	}
+	if err := attach(); err != nil {
+		_ = l.drop()
+		return lease{}, err
+	}
+	return l, nil
+}
+
+// detachShared releases the lease and purges the shared container when it was
+// the last one, under the lock of the leases.
+func detachShared(l lease, purge func() error) error {
+	unlock, err := lockLeases(l.name)
+	if err != nil {
+		return fmt.Errorf("%w: could not lock leases of shared container '%s': %w", ErrContainerPurge, l.name, err)
+	}
+	defer unlock()
+
+	last, err := releaseLease(l)
+	if err != nil || !last {
+		return err
+	}
+	return purge()
+}
+
+func leaseDir(name string) string {
+	return filepath.Join(os.TempDir(), reuseContainerPrefix+name+"-leases")
+}
+
+// lockLeases takes the exclusive lock on the leases of the shared container,
+// waiting for other processes to release it.
+func lockLeases(name string) (func(), error) {
+	dir := leaseDir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return lockFile(filepath.Join(dir, reuseLockFile))
+}
+
+// lease records that this process uses the shared container. Its file is
+// touched until it is dropped, so that only the leases of processes that died
+// without releasing them expire.
+type lease struct {
+	name string
+	path string
+	stop context.CancelFunc
+}
+
+// acquireLease takes a lease on the shared container with the given name.
+func acquireLease(name string) (lease, error) {
+	dir := leaseDir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return lease{}, err
+	}
+	randomBytes := make([]byte, 8)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return lease{}, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d-%s", os.Getpid(), hex.EncodeToString(randomBytes)))
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		return lease{}, err
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(reuseLeaseRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				_ = os.Chtimes(path, now, now)
+			}
+		}
+	}()
+	return lease{name: name, path: path, stop: stop}, nil
+}
+
+// drop stops refreshing the lease and removes it.
+func (l lease) drop() error {
+	l.stop()
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// releaseLease drops the lease and removes the expired ones of processes that
+// never released theirs, and reports whether no lease is left.
+func releaseLease(l lease) (bool, error) {
+	if err := l.drop(); err != nil {
+		return false, err
+	}
+
+	entries, err := os.ReadDir(leaseDir(l.name))
+	if err != nil {
+		return false, err
+	}
+	left := 0
+	for _, entry := range entries {
+		if entry.Name() == reuseLockFile {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Released by someone else meanwhile.
+			continue
+		}
+		if time.Since(info.ModTime()) > reuseLeaseTTL {
+			_ = os.Remove(filepath.Join(leaseDir(l.name), entry.Name()))
+			continue
+		}
+		left++
+	}
+	return left == 0, nil
+}
@@ -0,0 +1,65 @@
+package dynamotest
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSharedContainer_ConcurrentAttachAndDetach(t *testing.T) {
+	t.Parallel()
+
+	name := fmt.Sprintf("lease-test-%d", time.Now().UnixNano())
+	t.Cleanup(func() { _ = os.RemoveAll(leaseDir(name)) })
+
+	// Users holding a lease, which the purge of a stand-in for the shared
+	// container must never see.
+	var holders, purges, purgedWhileHeld atomic.Int32
+	attach := func() error { return nil }
+	purge := func() error {
+		purges.Add(1)
+		// Purging takes a while, during which another user may attach.
+		time.Sleep(2 * time.Millisecond)
+		if holders.Load() > 0 {
+			purgedWhileHeld.Add(1)
+		}
+		return nil
+	}
+
+	const users, leases = 4, 25
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < leases; j++ {
+				l, err := attachShared(name, attach)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				holders.Add(1)
+				time.Sleep(time.Millisecond)
+				holders.Add(-1)
+				if err := detachShared(l, purge); err != nil {
+					t.Error(err)
+					return
+				}
+				// Let the leases drop to zero now and then.
+				time.Sleep(time.Duration(j%3) * time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := purgedWhileHeld.Load(); n > 0 {
+		t.Errorf("shared container purged %d times while leased", n)
+	}
+	if purges.Load() == 0 {
+		t.Errorf("expected the shared container to be purged by its last user")
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !unix
+
+package dynamotest
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// lockFile takes an exclusive lock by creating the file, removing it once it
+// has been held for longer than any process would, as a process that died
+// holding it leaves it behind.
+func lockFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > reuseLockTTL {
+			_ = os.Remove(path)
+			continue
+		}
+		time.Sleep(reuseLockInterval)
+	}
+}
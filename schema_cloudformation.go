@@ -0,0 +1,429 @@
+package dynamotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	cfnTableType       = "AWS::DynamoDB::Table"
+	cfnSimpleTableType = "AWS::Serverless::SimpleTable"
+)
+
+// SchemaFromCloudFormation loads the schema of a table defined in a
+// CloudFormation or SAM template, in JSON or YAML, so tests use exactly the
+// production schema. Both AWS::DynamoDB::Table and
+// AWS::Serverless::SimpleTable resources are supported.
+//
+// logicalID is the logical ID of the table resource. For templates
+// synthesized by the CDK, the construct ID (e.g. "MyTable") is accepted as
+// well. It may be left empty when the template defines a single table.
+//
+// The table name is not loaded, as CreateTestingTable generates one.
+func SchemaFromCloudFormation(path, logicalID string) (dynamodb.CreateTableInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %w", ErrSchemaSource, err)
+	}
+
+	template, err := parseTemplate(data, filepath.Ext(path))
+	if err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %s: %w", ErrSchemaSource, path, err)
+	}
+
+	id, resource, err := findTableResource(template, logicalID)
+	if err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %s: %w", ErrSchemaSource, path, err)
+	}
+
+	props, _ := resource["Properties"].(map[string]any)
+	p := cfnPath("Resources." + id + ".Properties")
+	var schema dynamodb.CreateTableInput
+	if resource["Type"] == cfnSimpleTableType {
+		schema, err = simpleTableSchema(props, p)
+	} else {
+		schema, err = tableSchema(props, p)
+	}
+	if err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %s: %w", ErrSchemaSource, path, err)
+	}
+	return schema, nil
+}
+
+// parseTemplate decodes a JSON or YAML template. YAML short form intrinsic
+// functions such as !Ref are turned into their long form, so they can be
+// reported instead of being mistaken for literal values.
+func parseTemplate(data []byte, ext string) (map[string]any, error) {
+	var template map[string]any
+	if strings.EqualFold(ext, ".json") {
+		if err := json.Unmarshal(data, &template); err != nil {
+			return nil, err
+		}
+		return template, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("empty template")
+	}
+	v, err := yamlValue(doc.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	template, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("template is not a mapping")
+	}
+	return template, nil
+}
+
+// yamlValue converts a YAML node into the values encoding/json would produce
+// for the equivalent JSON template.
+func yamlValue(node *yaml.Node) (any, error) {
+	var v any
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[node.Content[i].Value] = value
+		}
+		v = m
+	case yaml.SequenceNode:
+		l := make([]any, 0, len(node.Content))
+		for _, n := range node.Content {
+			value, err := yamlValue(n)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, value)
+		}
+		v = l
+	default:
+		if err := node.Decode(&v); err != nil {
+			// Custom tags on scalars are decoded by their plain value.
+			v = node.Value
+		}
+	}
+
+	if strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!") {
+		name := strings.TrimPrefix(node.Tag, "!")
+		if name != "Ref" && name != "Condition" {
+			name = "Fn::" + name
+		}
+		return map[string]any{name: v}, nil
+	}
+	return v, nil
+}
+
+// findTableResource returns the table resource with the given logical ID.
+func findTableResource(template map[string]any, logicalID string) (string, map[string]any, error) {
+	resources, _ := template["Resources"].(map[string]any)
+
+	ids := make([]string, 0, len(resources))
+	for id, r := range resources {
+		resource, _ := r.(map[string]any)
+		if t := resource["Type"]; t == cfnTableType || t == cfnSimpleTableType {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	if logicalID == "" {
+		if len(ids) != 1 {
+			return "", nil, fmt.Errorf("template defines %d tables %v, a logical ID is needed", len(ids), ids)
+		}
+		return ids[0], resources[ids[0]].(map[string]any), nil
+	}
+
+	for _, id := range ids {
+		resource := resources[id].(map[string]any)
+		if id == logicalID {
+			return id, resource, nil
+		}
+		// The CDK derives logical IDs from the construct path with a hash
+		// suffix, but records the path in the metadata.
+		metadata, _ := resource["Metadata"].(map[string]any)
+		if cdkPath, ok := metadata["aws:cdk:path"].(string); ok && strings.HasSuffix(cdkPath, "/"+logicalID+"/Resource") {
+			return id, resource, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no table with logical ID '%s', found %v", logicalID, ids)
+}
+
+func tableSchema(props map[string]any, p cfnPath) (dynamodb.CreateTableInput, error) {
+	var (
+		schema dynamodb.CreateTableInput
+		err    error
+	)
+
+	for i, a := range cfnList(props["AttributeDefinitions"]) {
+		ap := p.index("AttributeDefinitions", i)
+		name, err := cfnString(a, "AttributeName", ap)
+		if err != nil {
+			return schema, err
+		}
+		attrType, err := cfnString(a, "AttributeType", ap)
+		if err != nil {
+			return schema, err
+		}
+		schema.AttributeDefinitions = append(schema.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: types.ScalarAttributeType(attrType),
+		})
+	}
+
+	if schema.KeySchema, err = cfnKeySchema(props["KeySchema"], p.field("KeySchema")); err != nil {
+		return schema, err
+	}
+
+	if billing, ok := props["BillingMode"]; ok {
+		s, err := cfnScalar(billing, p.field("BillingMode"))
+		if err != nil {
+			return schema, err
+		}
+		schema.BillingMode = types.BillingMode(s)
+	}
+
+	if schema.ProvisionedThroughput, err = cfnThroughput(props["ProvisionedThroughput"], p.field("ProvisionedThroughput")); err != nil {
+		return schema, err
+	}
+
+	for i, g := range cfnList(props["GlobalSecondaryIndexes"]) {
+		gp := p.index("GlobalSecondaryIndexes", i)
+		name, err := cfnString(g, "IndexName", gp)
+		if err != nil {
+			return schema, err
+		}
+		gsi := types.GlobalSecondaryIndex{IndexName: aws.String(name)}
+		if gsi.KeySchema, err = cfnKeySchema(g["KeySchema"], gp.field("KeySchema")); err != nil {
+			return schema, err
+		}
+		if gsi.Projection, err = cfnProjection(g["Projection"], gp.field("Projection")); err != nil {
+			return schema, err
+		}
+		if gsi.ProvisionedThroughput, err = cfnThroughput(g["ProvisionedThroughput"], gp.field("ProvisionedThroughput")); err != nil {
+			return schema, err
+		}
+		schema.GlobalSecondaryIndexes = append(schema.GlobalSecondaryIndexes, gsi)
+	}
+
+	for i, l := range cfnList(props["LocalSecondaryIndexes"]) {
+		lp := p.index("LocalSecondaryIndexes", i)
+		name, err := cfnString(l, "IndexName", lp)
+		if err != nil {
+			return schema, err
+		}
+		lsi := types.LocalSecondaryIndex{IndexName: aws.String(name)}
+		if lsi.KeySchema, err = cfnKeySchema(l["KeySchema"], lp.field("KeySchema")); err != nil {
+			return schema, err
+		}
+		if lsi.Projection, err = cfnProjection(l["Projection"], lp.field("Projection")); err != nil {
+			return schema, err
+		}
+		schema.LocalSecondaryIndexes = append(schema.LocalSecondaryIndexes, lsi)
+	}
+
+	if stream, ok := props["StreamSpecification"].(map[string]any); ok {
+		viewType, err := cfnString(stream, "StreamViewType", p.field("StreamSpecification"))
+		if err != nil {
+			return schema, err
+		}
+		schema.StreamSpecification = &types.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: types.StreamViewType(viewType),
+		}
+	}
+
+	return schema, nil
+}
+
+// simpleTableSchema converts a SAM SimpleTable, whose primary key defaults to
+// a string attribute named id.
+func simpleTableSchema(props map[string]any, p cfnPath) (dynamodb.CreateTableInput, error) {
+	name, attrType := "id", "String"
+	if pk, ok := props["PrimaryKey"].(map[string]any); ok {
+		var err error
+		if name, err = cfnString(pk, "Name", p.field("PrimaryKey")); err != nil {
+			return dynamodb.CreateTableInput{}, err
+		}
+		if attrType, err = cfnString(pk, "Type", p.field("PrimaryKey")); err != nil {
+			return dynamodb.CreateTableInput{}, err
+		}
+	}
+
+	scalar := map[string]types.ScalarAttributeType{
+		"String": types.ScalarAttributeTypeS,
+		"Number": types.ScalarAttributeTypeN,
+		"Binary": types.ScalarAttributeTypeB,
+	}[attrType]
+	if scalar == "" {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%s: unknown type '%s', expected String, Number or Binary", p.field("PrimaryKey.Type"), attrType)
+	}
+
+	schema := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(name), AttributeType: scalar},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(name), KeyType: types.KeyTypeHash},
+		},
+	}
+
+	var err error
+	if schema.ProvisionedThroughput, err = cfnThroughput(props["ProvisionedThroughput"], p.field("ProvisionedThroughput")); err != nil {
+		return schema, err
+	}
+	if schema.ProvisionedThroughput != nil {
+		schema.BillingMode = types.BillingModeProvisioned
+	} else {
+		schema.BillingMode = types.BillingModePayPerRequest
+	}
+	return schema, nil
+}
+
+func cfnKeySchema(v any, p cfnPath) ([]types.KeySchemaElement, error) {
+	var keys []types.KeySchemaElement
+	for i, k := range cfnList(v) {
+		kp := p.index("", i)
+		name, err := cfnString(k, "AttributeName", kp)
+		if err != nil {
+			return nil, err
+		}
+		keyType, err := cfnString(k, "KeyType", kp)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, types.KeySchemaElement{
+			AttributeName: aws.String(name),
+			KeyType:       types.KeyType(keyType),
+		})
+	}
+	return keys, nil
+}
+
+func cfnProjection(v any, p cfnPath) (*types.Projection, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	projection := &types.Projection{}
+	if t, ok := m["ProjectionType"]; ok {
+		s, err := cfnScalar(t, p.field("ProjectionType"))
+		if err != nil {
+			return nil, err
+		}
+		projection.ProjectionType = types.ProjectionType(s)
+	}
+	for i, a := range cfnListOf(m["NonKeyAttributes"]) {
+		s, err := cfnScalar(a, p.index("NonKeyAttributes", i))
+		if err != nil {
+			return nil, err
+		}
+		projection.NonKeyAttributes = append(projection.NonKeyAttributes, s)
+	}
+	return projection, nil
+}
+
+func cfnThroughput(v any, p cfnPath) (*types.ProvisionedThroughput, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	read, err := cfnInt(m["ReadCapacityUnits"], p.field("ReadCapacityUnits"))
+	if err != nil {
+		return nil, err
+	}
+	write, err := cfnInt(m["WriteCapacityUnits"], p.field("WriteCapacityUnits"))
+	if err != nil {
+		return nil, err
+	}
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(read),
+		WriteCapacityUnits: aws.Int64(write),
+	}, nil
+}
+
+// cfnPath is the location of a template value, used in error messages.
+type cfnPath string
+
+func (p cfnPath) field(name string) cfnPath {
+	return cfnPath(string(p) + "." + name)
+}
+
+func (p cfnPath) index(name string, i int) cfnPath {
+	if name != "" {
+		p = p.field(name)
+	}
+	return cfnPath(fmt.Sprintf("%s[%d]", p, i))
+}
+
+func cfnList(v any) []map[string]any {
+	var l []map[string]any
+	for _, e := range cfnListOf(v) {
+		m, _ := e.(map[string]any)
+		l = append(l, m)
+	}
+	return l
+}
+
+func cfnListOf(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func cfnString(m map[string]any, key string, p cfnPath) (string, error) {
+	v, ok := m[key]
+	if !ok {
+		return "", fmt.Errorf("%s: missing", p.field(key))
+	}
+	return cfnScalar(v, p.field(key))
+}
+
+// cfnScalar returns a literal value, failing on intrinsic functions as they
+// can only be resolved by CloudFormation.
+func cfnScalar(v any, p cfnPath) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int, bool:
+		return fmt.Sprint(v), nil
+	case map[string]any:
+		for fn := range v {
+			return "", fmt.Errorf("%s: intrinsic function %s is not supported, use a literal value", p, fn)
+		}
+	}
+	return "", fmt.Errorf("%s: expected a literal value, got %v", p, v)
+}
+
+func cfnInt(v any, p cfnPath) (int64, error) {
+	s, err := cfnScalar(v, p)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: expected a number, got '%s'", p, s)
+	}
+	return n, nil
+}
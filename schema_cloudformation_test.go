@@ -0,0 +1,171 @@
+package dynamotest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/rozen03/dynamotest"
+)
+
+// schemaCmpOpts ignores the unexported fields the SDK adds to its types.
+var schemaCmpOpts = cmpopts.IgnoreUnexported(
+	dynamodb.CreateTableInput{},
+	types.AttributeDefinition{},
+	types.KeySchemaElement{},
+	types.GlobalSecondaryIndex{},
+	types.LocalSecondaryIndex{},
+	types.Projection{},
+	types.ProvisionedThroughput{},
+	types.StreamSpecification{},
+)
+
+func TestSchemaFromCloudFormation(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		path      string
+		logicalID string
+		want      dynamodb.CreateTableInput
+	}{
+		"table with indexes and streams": {
+			path:      "testdata/cloudformation/template.yaml",
+			logicalID: "OrdersTable",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModeProvisioned,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("createdAt"), AttributeType: types.ScalarAttributeTypeN},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+					{
+						IndexName: aws.String("ByStatus"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+							{AttributeName: aws.String("createdAt"), KeyType: types.KeyTypeRange},
+						},
+						Projection: &types.Projection{
+							ProjectionType:   types.ProjectionTypeInclude,
+							NonKeyAttributes: []string{"total"},
+						},
+						ProvisionedThroughput: &types.ProvisionedThroughput{
+							ReadCapacityUnits:  aws.Int64(1),
+							WriteCapacityUnits: aws.Int64(1),
+						},
+					},
+				},
+				LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+					{
+						IndexName: aws.String("ByCreatedAt"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+							{AttributeName: aws.String("createdAt"), KeyType: types.KeyTypeRange},
+						},
+						Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+					},
+				},
+				StreamSpecification: &types.StreamSpecification{
+					StreamEnabled:  aws.Bool(true),
+					StreamViewType: types.StreamViewTypeNewAndOldImages,
+				},
+			},
+		},
+
+		"sam simple table": {
+			path: "testdata/cloudformation/sam.yaml",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModePayPerRequest,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("userId"), AttributeType: types.ScalarAttributeTypeN},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("userId"), KeyType: types.KeyTypeHash},
+				},
+			},
+		},
+
+		"cdk construct id": {
+			path:      "testdata/cloudformation/cdk.template.json",
+			logicalID: "SessionsTable",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModePayPerRequest,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("sessionId"), AttributeType: types.ScalarAttributeTypeS},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("sessionId"), KeyType: types.KeyTypeHash},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := dynamotest.SchemaFromCloudFormation(tc.path, tc.logicalID)
+			if err != nil {
+				t.Fatalf("failed to load schema: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, schemaCmpOpts); diff != "" {
+				t.Errorf("schema didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSchemaFromCloudFormation_Errors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		path      string
+		logicalID string
+		wantErr   string
+	}{
+		"intrinsic function": {
+			path:      "testdata/cloudformation/template.yaml",
+			logicalID: "BrokenTable",
+			wantErr:   "Resources.BrokenTable.Properties.AttributeDefinitions[0].AttributeName: intrinsic function Ref is not supported",
+		},
+		"unknown logical id": {
+			path:      "testdata/cloudformation/template.yaml",
+			logicalID: "Missing",
+			wantErr:   "no table with logical ID 'Missing'",
+		},
+		"ambiguous table": {
+			path:    "testdata/cloudformation/template.yaml",
+			wantErr: "template defines 2 tables",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := dynamotest.SchemaFromCloudFormation(tc.path, tc.logicalID)
+			if !errors.Is(err, dynamotest.ErrSchemaSource) {
+				t.Fatalf("expected ErrSchemaSource, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error to contain '%s', got '%v'", tc.wantErr, err)
+			}
+		})
+	}
+}
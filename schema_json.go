@@ -0,0 +1,164 @@
+package dynamotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SchemaFromJSON loads a schema in the JSON format of the AWS CLI, either the
+// output of `aws dynamodb describe-table` or a `create-table
+// --cli-input-json` file. Read-only fields such as TableStatus, ItemCount,
+// ARNs and capacity descriptions are dropped, so the result can be passed to
+// CreateTestingTable as is.
+func SchemaFromJSON(r io.Reader) (dynamodb.CreateTableInput, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %w", ErrSchemaSource, err)
+	}
+
+	// describe-table wraps the description in a Table field.
+	var describe struct {
+		Table json.RawMessage
+	}
+	if err := json.Unmarshal(data, &describe); err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %w", ErrSchemaSource, err)
+	}
+	if len(describe.Table) > 0 {
+		data = describe.Table
+	}
+
+	var table jsonTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %w", ErrSchemaSource, err)
+	}
+	if len(table.KeySchema) == 0 {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: no KeySchema found, expected describe-table or create-table JSON", ErrSchemaSource)
+	}
+	return table.schema(), nil
+}
+
+// jsonTable holds the fields shared by describe-table and create-table that
+// make up a schema.
+type jsonTable struct {
+	AttributeDefinitions []struct {
+		AttributeName string
+		AttributeType string
+	}
+	KeySchema          []jsonKey
+	BillingMode        string
+	BillingModeSummary *struct {
+		BillingMode string
+	}
+	ProvisionedThroughput  *jsonThroughput
+	GlobalSecondaryIndexes []jsonIndex
+	LocalSecondaryIndexes  []jsonIndex
+	StreamSpecification    *struct {
+		StreamEnabled  bool
+		StreamViewType string
+	}
+}
+
+type jsonKey struct {
+	AttributeName string
+	KeyType       string
+}
+
+type jsonThroughput struct {
+	ReadCapacityUnits  int64
+	WriteCapacityUnits int64
+}
+
+type jsonIndex struct {
+	IndexName  string
+	KeySchema  []jsonKey
+	Projection *struct {
+		ProjectionType   string
+		NonKeyAttributes []string
+	}
+	ProvisionedThroughput *jsonThroughput
+}
+
+func (t jsonTable) schema() dynamodb.CreateTableInput {
+	var schema dynamodb.CreateTableInput
+
+	for _, a := range t.AttributeDefinitions {
+		schema.AttributeDefinitions = append(schema.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(a.AttributeName),
+			AttributeType: types.ScalarAttributeType(a.AttributeType),
+		})
+	}
+	schema.KeySchema = jsonKeySchema(t.KeySchema)
+
+	schema.BillingMode = types.BillingMode(t.BillingMode)
+	if t.BillingModeSummary != nil {
+		schema.BillingMode = types.BillingMode(t.BillingModeSummary.BillingMode)
+	}
+	schema.ProvisionedThroughput = t.ProvisionedThroughput.throughput()
+	if schema.BillingMode == "" && schema.ProvisionedThroughput != nil {
+		schema.BillingMode = types.BillingModeProvisioned
+	}
+
+	for _, g := range t.GlobalSecondaryIndexes {
+		schema.GlobalSecondaryIndexes = append(schema.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+			IndexName:             aws.String(g.IndexName),
+			KeySchema:             jsonKeySchema(g.KeySchema),
+			Projection:            g.projection(),
+			ProvisionedThroughput: g.ProvisionedThroughput.throughput(),
+		})
+	}
+	for _, l := range t.LocalSecondaryIndexes {
+		schema.LocalSecondaryIndexes = append(schema.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  aws.String(l.IndexName),
+			KeySchema:  jsonKeySchema(l.KeySchema),
+			Projection: l.projection(),
+		})
+	}
+
+	if t.StreamSpecification != nil && t.StreamSpecification.StreamEnabled {
+		schema.StreamSpecification = &types.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: types.StreamViewType(t.StreamSpecification.StreamViewType),
+		}
+	}
+
+	return schema
+}
+
+func jsonKeySchema(keys []jsonKey) []types.KeySchemaElement {
+	schema := make([]types.KeySchemaElement, 0, len(keys))
+	for _, k := range keys {
+		schema = append(schema, types.KeySchemaElement{
+			AttributeName: aws.String(k.AttributeName),
+			KeyType:       types.KeyType(k.KeyType),
+		})
+	}
+	return schema
+}
+
+func (i jsonIndex) projection() *types.Projection {
+	if i.Projection == nil {
+		return nil
+	}
+	projection := &types.Projection{ProjectionType: types.ProjectionType(i.Projection.ProjectionType)}
+	if len(i.Projection.NonKeyAttributes) > 0 {
+		projection.NonKeyAttributes = i.Projection.NonKeyAttributes
+	}
+	return projection
+}
+
+// throughput drops the capacity describe-table reports as zero for on-demand
+// tables.
+func (t *jsonThroughput) throughput() *types.ProvisionedThroughput {
+	if t == nil || (t.ReadCapacityUnits == 0 && t.WriteCapacityUnits == 0) {
+		return nil
+	}
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(t.ReadCapacityUnits),
+		WriteCapacityUnits: aws.Int64(t.WriteCapacityUnits),
+	}
+}
@@ -0,0 +1,113 @@
+package dynamotest_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestSchemaFromJSON(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		path string
+		want dynamodb.CreateTableInput
+	}{
+		"describe-table output": {
+			path: "testdata/json/describe-table.json",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModePayPerRequest,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+				},
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+					{
+						IndexName: aws.String("ByStatus"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+						},
+						Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+					},
+				},
+				StreamSpecification: &types.StreamSpecification{
+					StreamEnabled:  aws.Bool(true),
+					StreamViewType: types.StreamViewTypeKeysOnly,
+				},
+			},
+		},
+
+		"create-table cli input": {
+			path: "testdata/json/create-table.json",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModeProvisioned,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeN},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(10),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+				LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+					{
+						IndexName: aws.String("Latest"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+							{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+						},
+						Projection: &types.Projection{
+							ProjectionType:   types.ProjectionTypeInclude,
+							NonKeyAttributes: []string{"payload"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := os.Open(tc.path)
+			if err != nil {
+				t.Fatalf("failed to open %s: %v", tc.path, err)
+			}
+			defer f.Close()
+
+			got, err := dynamotest.SchemaFromJSON(f)
+			if err != nil {
+				t.Fatalf("failed to load schema: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, schemaCmpOpts); diff != "" {
+				t.Errorf("schema didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSchemaFromJSON_NoKeySchema(t *testing.T) {
+	t.Parallel()
+
+	_, err := dynamotest.SchemaFromJSON(strings.NewReader(`{"TableNames": ["orders"]}`))
+	if !errors.Is(err, dynamotest.ErrSchemaSource) {
+		t.Fatalf("expected ErrSchemaSource, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package dynamotest
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SchemaFromStruct derives a schema from the dynamotest tags of the fields of
+// T, so models annotated for attributevalue do not need a hand written schema.
+//
+// The tag lists the key roles of the field, separated by semicolons:
+//
+//	type Order struct {
+//		ID        string    `dynamodbav:"pk" dynamotest:"hash"`
+//		CreatedAt time.Time `dynamodbav:"createdAt" dynamotest:"range;lsi=ByCreatedAt"`
+//		Status    string    `dynamodbav:"status" dynamotest:"gsi=ByStatus,hash"`
+//		Total     int       `dynamodbav:"total" dynamotest:"gsi=ByStatus,range"`
+//	}
+//
+// "hash" and "range" are the keys of the table, "gsi=Name,hash" and
+// "gsi=Name,range" the keys of a global secondary index, and "lsi=Name" the
+// range key of a local secondary index. Indexes project all attributes.
+//
+// Attribute names follow the dynamodbav tag like attributevalue does, and
+// their types are inferred from the Go types: strings and time.Time are S,
+// numbers are N and byte slices are B. The ",string" option of dynamodbav
+// makes any of them S, and the ",unixtime" option makes time.Time N.
+func SchemaFromStruct[T any]() (dynamodb.CreateTableInput, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %s is not a struct", ErrSchemaSource, t)
+	}
+
+	b := structSchema{attributes: map[string]types.ScalarAttributeType{}}
+	if err := b.addFields(t); err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %s: %w", ErrSchemaSource, t, err)
+	}
+	schema, err := b.schema()
+	if err != nil {
+		return dynamodb.CreateTableInput{}, fmt.Errorf("%w: %s: %w", ErrSchemaSource, t, err)
+	}
+	return schema, nil
+}
+
+// structSchema collects the keys found in the struct tags.
+type structSchema struct {
+	attributes     map[string]types.ScalarAttributeType
+	attributeOrder []string
+
+	table   structKeys
+	gsis    map[string]*structKeys
+	lsis    map[string]*structKeys
+	indexes []string
+}
+
+type structKeys struct {
+	hash, rangeKey string
+}
+
+func (k *structKeys) set(role, attribute, where string) error {
+	current := &k.hash
+	if role == "range" {
+		current = &k.rangeKey
+	} else if role != "hash" {
+		return fmt.Errorf("unknown key role '%s' for %s, expected hash or range", role, where)
+	}
+	if *current != "" && *current != attribute {
+		return fmt.Errorf("%s has two %s keys, '%s' and '%s'", where, role, *current, attribute)
+	}
+	*current = attribute
+	return nil
+}
+
+func (b *structSchema) addFields(t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts := parseAVTag(field)
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs are flattened by attributevalue unless named.
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := b.addFields(ft); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("dynamotest")
+		if !ok || tag == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		attrType, err := scalarType(field.Type, opts)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if _, ok := b.attributes[name]; !ok {
+			b.attributeOrder = append(b.attributeOrder, name)
+		}
+		b.attributes[name] = attrType
+
+		for _, role := range strings.Split(tag, ";") {
+			if err := b.addRole(strings.TrimSpace(role), name); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *structSchema) addRole(role, attribute string) error {
+	switch {
+	case role == "hash" || role == "range":
+		return b.table.set(role, attribute, "table")
+
+	case strings.HasPrefix(role, "gsi="):
+		index, keyRole, _ := strings.Cut(strings.TrimPrefix(role, "gsi="), ",")
+		if index == "" {
+			return fmt.Errorf("global secondary index in '%s' has no name", role)
+		}
+		if _, ok := b.lsis[index]; ok {
+			return fmt.Errorf("index '%s' is both a global and a local secondary index, index names must be unique", index)
+		}
+		return b.index(&b.gsis, index).set(keyRole, attribute, "global secondary index '"+index+"'")
+
+	case strings.HasPrefix(role, "lsi="):
+		index, keyRole, _ := strings.Cut(strings.TrimPrefix(role, "lsi="), ",")
+		if index == "" {
+			return fmt.Errorf("local secondary index in '%s' has no name", role)
+		}
+		if _, ok := b.gsis[index]; ok {
+			return fmt.Errorf("index '%s' is both a global and a local secondary index, index names must be unique", index)
+		}
+		if keyRole != "" && keyRole != "range" {
+			return fmt.Errorf("local secondary index '%s' can only set the range key, its hash key is the one of the table", index)
+		}
+		return b.index(&b.lsis, index).set("range", attribute, "local secondary index '"+index+"'")
+	}
+	return fmt.Errorf("unknown dynamotest tag '%s'", role)
+}
+
+func (b *structSchema) index(indexes *map[string]*structKeys, name string) *structKeys {
+	if *indexes == nil {
+		*indexes = map[string]*structKeys{}
+	}
+	keys, ok := (*indexes)[name]
+	if !ok {
+		keys = &structKeys{}
+		(*indexes)[name] = keys
+		b.indexes = append(b.indexes, name)
+	}
+	return keys
+}
+
+func (b *structSchema) schema() (dynamodb.CreateTableInput, error) {
+	var schema dynamodb.CreateTableInput
+	if b.table.hash == "" {
+		return schema, fmt.Errorf("no field is tagged with dynamotest:\"hash\"")
+	}
+
+	for _, name := range b.attributeOrder {
+		schema.AttributeDefinitions = append(schema.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: b.attributes[name],
+		})
+	}
+	schema.KeySchema = structKeySchema(b.table)
+
+	for _, name := range b.indexes {
+		if keys, ok := b.gsis[name]; ok {
+			if keys.hash == "" {
+				return schema, fmt.Errorf("global secondary index '%s' has no hash key", name)
+			}
+			schema.GlobalSecondaryIndexes = append(schema.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+				IndexName:  aws.String(name),
+				KeySchema:  structKeySchema(*keys),
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			})
+		}
+		if keys, ok := b.lsis[name]; ok {
+			schema.LocalSecondaryIndexes = append(schema.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+				IndexName:  aws.String(name),
+				KeySchema:  structKeySchema(structKeys{hash: b.table.hash, rangeKey: keys.rangeKey}),
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			})
+		}
+	}
+	return schema, nil
+}
+
+func structKeySchema(keys structKeys) []types.KeySchemaElement {
+	schema := []types.KeySchemaElement{
+		{AttributeName: aws.String(keys.hash), KeyType: types.KeyTypeHash},
+	}
+	if keys.rangeKey != "" {
+		schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(keys.rangeKey), KeyType: types.KeyTypeRange})
+	}
+	return schema
+}
+
+// parseAVTag returns the attribute name and options of the dynamodbav tag.
+func parseAVTag(field reflect.StructField) (string, []string) {
+	tag := field.Tag.Get("dynamodbav")
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// scalarType infers the key attribute type the way attributevalue encodes the
+// Go type.
+func scalarType(t reflect.Type, opts []string) (types.ScalarAttributeType, error) {
+	for _, opt := range opts {
+		if opt == "string" {
+			return types.ScalarAttributeTypeS, nil
+		}
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		// attributevalue encodes times as RFC 3339 strings, or as seconds
+		// since the epoch with the unixtime option.
+		if slices.Contains(opts, "unixtime") {
+			return types.ScalarAttributeTypeN, nil
+		}
+		return types.ScalarAttributeTypeS, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return types.ScalarAttributeTypeS, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return types.ScalarAttributeTypeN, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return types.ScalarAttributeTypeB, nil
+		}
+	}
+	return "", fmt.Errorf("%s cannot be a key attribute, only strings, numbers and binary can", t)
+}
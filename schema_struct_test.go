@@ -0,0 +1,162 @@
+package dynamotest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest"
+)
+
+type auditFields struct {
+	CreatedAt time.Time `dynamodbav:"createdAt" dynamotest:"lsi=ByCreatedAt;gsi=ByStatus,range"`
+}
+
+type order struct {
+	auditFields
+	ID       string            `dynamodbav:"pk" dynamotest:"hash"`
+	Version  int               `dynamodbav:"sk" dynamotest:"range"`
+	Status   string            `dynamodbav:"status" dynamotest:"gsi=ByStatus,hash"`
+	Checksum []byte            `dynamodbav:"checksum" dynamotest:"gsi=ByChecksum,hash"`
+	Customer int64             `dynamodbav:"customer,string" dynamotest:"gsi=ByChecksum,range"`
+	Expires  time.Time         `dynamodbav:"expiresAt,unixtime" dynamotest:"gsi=ByExpiry,hash"`
+	Items    map[string]string `dynamodbav:"items"`
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	t.Parallel()
+
+	got, err := dynamotest.SchemaFromStruct[order]()
+	if err != nil {
+		t.Fatalf("failed to derive schema: %v", err)
+	}
+
+	want := dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("createdAt"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("checksum"), AttributeType: types.ScalarAttributeTypeB},
+			{AttributeName: aws.String("customer"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("expiresAt"), AttributeType: types.ScalarAttributeTypeN},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String("ByStatus"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("createdAt"), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+			{
+				IndexName: aws.String("ByChecksum"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("checksum"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("customer"), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+			{
+				IndexName: aws.String("ByExpiry"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("expiresAt"), KeyType: types.KeyTypeHash},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+		LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+			{
+				IndexName: aws.String("ByCreatedAt"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("createdAt"), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got, schemaCmpOpts); diff != "" {
+		t.Errorf("schema didn't match (-want / +got)\n%s", diff)
+	}
+}
+
+func TestSchemaFromStruct_Errors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		derive  func() (dynamodb.CreateTableInput, error)
+		wantErr string
+	}{
+		"no hash key": {
+			derive: dynamotest.SchemaFromStruct[struct {
+				ID string `dynamotest:"range"`
+			}],
+			wantErr: `no field is tagged with dynamotest:"hash"`,
+		},
+		"two hash keys": {
+			derive: dynamotest.SchemaFromStruct[struct {
+				ID    string `dynamotest:"hash"`
+				Other string `dynamotest:"hash"`
+			}],
+			wantErr: "table has two hash keys, 'ID' and 'Other'",
+		},
+		"unsupported key type": {
+			derive: dynamotest.SchemaFromStruct[struct {
+				ID bool `dynamotest:"hash"`
+			}],
+			wantErr: "field ID: bool cannot be a key attribute",
+		},
+		"index without hash key": {
+			derive: dynamotest.SchemaFromStruct[struct {
+				ID     string `dynamotest:"hash"`
+				Status string `dynamotest:"gsi=ByStatus,range"`
+			}],
+			wantErr: "global secondary index 'ByStatus' has no hash key",
+		},
+		"index name clash": {
+			derive: dynamotest.SchemaFromStruct[struct {
+				ID        string `dynamotest:"hash"`
+				Status    string `dynamotest:"gsi=ByStatus,hash"`
+				CreatedAt string `dynamotest:"range;lsi=ByStatus"`
+			}],
+			wantErr: "field CreatedAt: index 'ByStatus' is both a global and a local secondary index",
+		},
+		"unknown tag": {
+			derive: dynamotest.SchemaFromStruct[struct {
+				ID string `dynamotest:"partition"`
+			}],
+			wantErr: "unknown dynamotest tag 'partition'",
+		},
+		"not a struct": {
+			derive:  dynamotest.SchemaFromStruct[string],
+			wantErr: "string is not a struct",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := tc.derive()
+			if !errors.Is(err, dynamotest.ErrSchemaSource) {
+				t.Fatalf("expected ErrSchemaSource, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error to contain '%s', got '%v'", tc.wantErr, err)
+			}
+		})
+	}
+}
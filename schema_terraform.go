@@ -0,0 +1,348 @@
+package dynamotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+const tfTableType = "aws_dynamodb_table"
+
+// TableDefinition is a table loaded from infrastructure code. TimeToLive is
+// kept apart from the schema, as DynamoDB only accepts it through
+// UpdateTimeToLive once the table exists, which
+// Client.CreateTestingTableFromDefinition does.
+type TableDefinition struct {
+	Schema     dynamodb.CreateTableInput
+	TimeToLive *types.TimeToLiveSpecification
+}
+
+// SchemaFromTerraform loads the schema of an aws_dynamodb_table resource, see
+// TableFromTerraform. The time to live settings are left out, as the schema
+// cannot hold them.
+func SchemaFromTerraform(path, name string) (dynamodb.CreateTableInput, error) {
+	table, err := TableFromTerraform(path, name)
+	return table.Schema, err
+}
+
+// TableFromTerraform loads an aws_dynamodb_table resource so tests use exactly
+// the production schema, including its keys, attributes, global and local
+// secondary indexes, capacity, streams and time to live settings.
+//
+// path is either a Terraform configuration file (.tf or .tf.json), a
+// directory of them, or the output of `terraform show -json` (.json) for a
+// state or a plan. In configuration files every value the table needs must be
+// a literal, as variables cannot be resolved.
+//
+// name is the resource name (e.g. "orders") or its address (e.g.
+// "module.app.aws_dynamodb_table.orders"). Instances of resources with count
+// or for_each are selected with their key (e.g. "orders[0]"). name may be left
+// empty when a single table is defined.
+func TableFromTerraform(path, name string) (TableDefinition, error) {
+	tables, err := loadTerraformTables(path)
+	if err != nil {
+		return TableDefinition{}, fmt.Errorf("%w: %s: %w", ErrSchemaSource, path, err)
+	}
+
+	addresses := make([]string, 0, len(tables))
+	for address := range tables {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	var matches []string
+	for _, address := range addresses {
+		if tfAddressMatches(address, name) || tfAddressMatches(tfResourceAddress(address), name) {
+			matches = append(matches, address)
+		}
+	}
+	if len(matches) != 1 {
+		return TableDefinition{}, fmt.Errorf("%w: %s: %d tables match '%s', found %v", ErrSchemaSource, path, len(matches), name, addresses)
+	}
+
+	table, err := tables[matches[0]].definition()
+	if err != nil {
+		return TableDefinition{}, fmt.Errorf("%w: %s: %s: %w", ErrSchemaSource, path, matches[0], err)
+	}
+	return table, nil
+}
+
+func tfAddressMatches(address, name string) bool {
+	return name == "" || address == name || strings.HasSuffix(address, "."+name)
+}
+
+// tfResourceAddress strips the instance key from the address of a resource
+// with count or for_each, e.g. aws_dynamodb_table.orders[0], so that its only
+// instance can be selected by the resource name.
+func tfResourceAddress(address string) string {
+	i := strings.LastIndex(address, tfTableType+".")
+	if i < 0 {
+		return address
+	}
+	resource := address[i:]
+	if j := strings.IndexByte(resource, '['); j >= 0 {
+		return address[:i+j]
+	}
+	return address
+}
+
+// tfTable holds the aws_dynamodb_table arguments the schema is made of, as
+// they appear in both the configuration and `terraform show -json`.
+type tfTable struct {
+	HashKey        string         `hcl:"hash_key" json:"hash_key"`
+	RangeKey       *string        `hcl:"range_key,optional" json:"range_key"`
+	BillingMode    *string        `hcl:"billing_mode,optional" json:"billing_mode"`
+	ReadCapacity   *int64         `hcl:"read_capacity,optional" json:"read_capacity"`
+	WriteCapacity  *int64         `hcl:"write_capacity,optional" json:"write_capacity"`
+	StreamEnabled  *bool          `hcl:"stream_enabled,optional" json:"stream_enabled"`
+	StreamViewType *string        `hcl:"stream_view_type,optional" json:"stream_view_type"`
+	Attributes     []tfAttribute  `hcl:"attribute,block" json:"attribute"`
+	GSIs           []tfIndex      `hcl:"global_secondary_index,block" json:"global_secondary_index"`
+	LSIs           []tfIndex      `hcl:"local_secondary_index,block" json:"local_secondary_index"`
+	TTL            []tfTimeToLive `hcl:"ttl,block" json:"ttl"`
+	Remain         hcl.Body       `hcl:",remain" json:"-"`
+}
+
+type tfAttribute struct {
+	Name   string   `hcl:"name" json:"name"`
+	Type   string   `hcl:"type" json:"type"`
+	Remain hcl.Body `hcl:",remain" json:"-"`
+}
+
+type tfIndex struct {
+	Name             string   `hcl:"name" json:"name"`
+	HashKey          *string  `hcl:"hash_key,optional" json:"hash_key"`
+	RangeKey         *string  `hcl:"range_key,optional" json:"range_key"`
+	ProjectionType   string   `hcl:"projection_type" json:"projection_type"`
+	NonKeyAttributes []string `hcl:"non_key_attributes,optional" json:"non_key_attributes"`
+	ReadCapacity     *int64   `hcl:"read_capacity,optional" json:"read_capacity"`
+	WriteCapacity    *int64   `hcl:"write_capacity,optional" json:"write_capacity"`
+	Remain           hcl.Body `hcl:",remain" json:"-"`
+}
+
+type tfTimeToLive struct {
+	AttributeName *string  `hcl:"attribute_name,optional" json:"attribute_name"`
+	Enabled       *bool    `hcl:"enabled,optional" json:"enabled"`
+	Remain        hcl.Body `hcl:",remain" json:"-"`
+}
+
+// definition converts the resource, applying the provider defaults.
+func (t tfTable) definition() (TableDefinition, error) {
+	var schema dynamodb.CreateTableInput
+
+	for _, a := range t.Attributes {
+		schema.AttributeDefinitions = append(schema.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(a.Name),
+			AttributeType: types.ScalarAttributeType(a.Type),
+		})
+	}
+	schema.KeySchema = tfKeySchema(&t.HashKey, t.RangeKey)
+
+	// The provider defaults to provisioned capacity.
+	schema.BillingMode = types.BillingModeProvisioned
+	if t.BillingMode != nil && *t.BillingMode != "" {
+		schema.BillingMode = types.BillingMode(*t.BillingMode)
+	}
+	provisioned := schema.BillingMode == types.BillingModeProvisioned
+	if provisioned {
+		schema.ProvisionedThroughput = tfThroughput(t.ReadCapacity, t.WriteCapacity)
+	}
+
+	for _, g := range t.GSIs {
+		if g.HashKey == nil || *g.HashKey == "" {
+			return TableDefinition{}, fmt.Errorf("global_secondary_index '%s' has no hash_key", g.Name)
+		}
+		gsi := types.GlobalSecondaryIndex{
+			IndexName:  aws.String(g.Name),
+			KeySchema:  tfKeySchema(g.HashKey, g.RangeKey),
+			Projection: tfProjection(g),
+		}
+		if provisioned {
+			gsi.ProvisionedThroughput = tfThroughput(g.ReadCapacity, g.WriteCapacity)
+		}
+		schema.GlobalSecondaryIndexes = append(schema.GlobalSecondaryIndexes, gsi)
+	}
+
+	for _, l := range t.LSIs {
+		// Local indexes share the hash key of the table.
+		schema.LocalSecondaryIndexes = append(schema.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  aws.String(l.Name),
+			KeySchema:  tfKeySchema(&t.HashKey, l.RangeKey),
+			Projection: tfProjection(l),
+		})
+	}
+
+	if aws.ToBool(t.StreamEnabled) {
+		schema.StreamSpecification = &types.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: types.StreamViewType(aws.ToString(t.StreamViewType)),
+		}
+	}
+
+	table := TableDefinition{Schema: schema}
+	if len(t.TTL) > 0 && aws.ToString(t.TTL[0].AttributeName) != "" {
+		table.TimeToLive = &types.TimeToLiveSpecification{
+			AttributeName: t.TTL[0].AttributeName,
+			Enabled:       aws.Bool(aws.ToBool(t.TTL[0].Enabled)),
+		}
+	}
+	return table, nil
+}
+
+func tfKeySchema(hashKey, rangeKey *string) []types.KeySchemaElement {
+	keys := []types.KeySchemaElement{
+		{AttributeName: aws.String(aws.ToString(hashKey)), KeyType: types.KeyTypeHash},
+	}
+	if rangeKey != nil && *rangeKey != "" {
+		keys = append(keys, types.KeySchemaElement{AttributeName: aws.String(*rangeKey), KeyType: types.KeyTypeRange})
+	}
+	return keys
+}
+
+func tfProjection(index tfIndex) *types.Projection {
+	projection := &types.Projection{ProjectionType: types.ProjectionType(index.ProjectionType)}
+	// The state lists no attributes as an empty list, which CreateTable rejects.
+	if len(index.NonKeyAttributes) > 0 {
+		projection.NonKeyAttributes = index.NonKeyAttributes
+	}
+	return projection
+}
+
+func tfThroughput(read, write *int64) *types.ProvisionedThroughput {
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(aws.ToInt64(read)),
+		WriteCapacityUnits: aws.Int64(aws.ToInt64(write)),
+	}
+}
+
+// loadTerraformTables returns the tables defined at path by address.
+func loadTerraformTables(path string) (map[string]tfTable, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	switch {
+	case info.IsDir():
+		for _, pattern := range []string{"*.tf", "*.tf.json"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, matches...)
+		}
+	case strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".tf.json"):
+		return loadTerraformShow(path)
+	default:
+		files = []string{path}
+	}
+
+	tables := make(map[string]tfTable)
+	parser := hclparse.NewParser()
+	for _, file := range files {
+		var (
+			f     *hcl.File
+			diags hcl.Diagnostics
+		)
+		if strings.HasSuffix(file, ".json") {
+			f, diags = parser.ParseJSONFile(file)
+		} else {
+			f, diags = parser.ParseHCLFile(file)
+		}
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		content, _, diags := f.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: "resource", LabelNames: []string{"type", "name"}}},
+		})
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		for _, block := range content.Blocks {
+			if block.Labels[0] != tfTableType {
+				continue
+			}
+			var table tfTable
+			if diags := gohcl.DecodeBody(block.Body, nil, &table); diags.HasErrors() {
+				return nil, diags
+			}
+			tables[tfTableType+"."+block.Labels[1]] = table
+		}
+	}
+	return tables, nil
+}
+
+// tfModule is a module in the output of `terraform show -json`.
+type tfModule struct {
+	Resources []struct {
+		Address string          `json:"address"`
+		Mode    string          `json:"mode"`
+		Type    string          `json:"type"`
+		Values  json.RawMessage `json:"values"`
+	} `json:"resources"`
+	ChildModules []tfModule `json:"child_modules"`
+}
+
+// loadTerraformShow reads the tables of a state, or the planned ones of a plan.
+func loadTerraformShow(path string) (map[string]tfTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var show struct {
+		Values *struct {
+			RootModule tfModule `json:"root_module"`
+		} `json:"values"`
+		PlannedValues *struct {
+			RootModule tfModule `json:"root_module"`
+		} `json:"planned_values"`
+	}
+	if err := json.Unmarshal(data, &show); err != nil {
+		return nil, err
+	}
+
+	var root tfModule
+	switch {
+	case show.Values != nil:
+		root = show.Values.RootModule
+	case show.PlannedValues != nil:
+		root = show.PlannedValues.RootModule
+	default:
+		return nil, fmt.Errorf("neither values nor planned_values found, expected the output of terraform show -json")
+	}
+
+	tables := make(map[string]tfTable)
+	var walk func(m tfModule) error
+	walk = func(m tfModule) error {
+		for _, r := range m.Resources {
+			if r.Type != tfTableType || r.Mode == "data" {
+				continue
+			}
+			var table tfTable
+			if err := json.Unmarshal(r.Values, &table); err != nil {
+				return fmt.Errorf("%s: %w", r.Address, err)
+			}
+			tables[r.Address] = table
+		}
+		for _, child := range m.ChildModules {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return tables, walk(root)
+}
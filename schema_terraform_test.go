@@ -0,0 +1,200 @@
+package dynamotest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestTableFromTerraform(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		path string
+		name string
+		want dynamodb.CreateTableInput
+		ttl  *types.TimeToLiveSpecification
+	}{
+		"configuration": {
+			path: "testdata/terraform/config",
+			name: "orders",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModeProvisioned,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("createdAt"), AttributeType: types.ScalarAttributeTypeN},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+				},
+				ProvisionedThroughput: &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+					{
+						IndexName: aws.String("ByStatus"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+							{AttributeName: aws.String("createdAt"), KeyType: types.KeyTypeRange},
+						},
+						Projection: &types.Projection{
+							ProjectionType:   types.ProjectionTypeInclude,
+							NonKeyAttributes: []string{"total"},
+						},
+						ProvisionedThroughput: &types.ProvisionedThroughput{
+							ReadCapacityUnits:  aws.Int64(1),
+							WriteCapacityUnits: aws.Int64(1),
+						},
+					},
+				},
+				LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+					{
+						IndexName: aws.String("ByCreatedAt"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+							{AttributeName: aws.String("createdAt"), KeyType: types.KeyTypeRange},
+						},
+						Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+					},
+				},
+				StreamSpecification: &types.StreamSpecification{
+					StreamEnabled:  aws.Bool(true),
+					StreamViewType: types.StreamViewTypeNewImage,
+				},
+			},
+			ttl: &types.TimeToLiveSpecification{
+				AttributeName: aws.String("expiresAt"),
+				Enabled:       aws.Bool(true),
+			},
+		},
+
+		"count instance": {
+			path: "testdata/terraform/count.json",
+			name: "orders[1]",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModePayPerRequest,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+				},
+			},
+		},
+
+		"terraform show json": {
+			path: "testdata/terraform/show.json",
+			name: "aws_dynamodb_table.sessions",
+			want: dynamodb.CreateTableInput{
+				BillingMode: types.BillingModePayPerRequest,
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("userId"), AttributeType: types.ScalarAttributeTypeS},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+				},
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+					{
+						IndexName: aws.String("ByUser"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("userId"), KeyType: types.KeyTypeHash},
+						},
+						Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := dynamotest.TableFromTerraform(tc.path, tc.name)
+			if err != nil {
+				t.Fatalf("failed to load table: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got.Schema, schemaCmpOpts); diff != "" {
+				t.Errorf("schema didn't match (-want / +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.ttl, got.TimeToLive, cmpopts.IgnoreUnexported(types.TimeToLiveSpecification{})); diff != "" {
+				t.Errorf("time to live didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateTestingTableFromDefinition(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	definition, err := dynamotest.TableFromTerraform("testdata/terraform/config", "orders")
+	if err != nil {
+		t.Fatalf("failed to load table: %v", err)
+	}
+	table := client.CreateTestingTableFromDefinition(t, "terraform", definition)
+
+	out, err := client.DescribeTimeToLive(context.Background(), &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(table)})
+	if err != nil {
+		t.Fatalf("failed to describe time to live: %v", err)
+	}
+	ttl := out.TimeToLiveDescription
+	if ttl.TimeToLiveStatus != types.TimeToLiveStatusEnabled || aws.ToString(ttl.AttributeName) != "expiresAt" {
+		t.Errorf("expected time to live enabled on 'expiresAt', got %s on '%s'", ttl.TimeToLiveStatus, aws.ToString(ttl.AttributeName))
+	}
+}
+
+func TestSchemaFromTerraform_Errors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		path    string
+		name    string
+		wantErr string
+	}{
+		"variable reference": {
+			path:    "testdata/terraform/broken/main.tf",
+			wantErr: "main.tf:8",
+		},
+		"unknown table": {
+			path:    "testdata/terraform/config/main.tf",
+			name:    "missing",
+			wantErr: "0 tables match 'missing', found [aws_dynamodb_table.orders]",
+		},
+		"count without key": {
+			path:    "testdata/terraform/count.json",
+			name:    "orders",
+			wantErr: "2 tables match 'orders', found [aws_dynamodb_table.orders[0] aws_dynamodb_table.orders[1]]",
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := dynamotest.SchemaFromTerraform(tc.path, tc.name)
+			if !errors.Is(err, dynamotest.ErrSchemaSource) {
+				t.Fatalf("expected ErrSchemaSource, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error to contain '%s', got '%v'", tc.wantErr, err)
+			}
+		})
+	}
+}
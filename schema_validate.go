@@ -0,0 +1,225 @@
+package dynamotest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Limits of CreateTable checked by ValidateSchema.
+const (
+	maxGlobalSecondaryIndexes = 20
+	maxLocalSecondaryIndexes  = 5
+	maxProjectedAttributes    = 100
+)
+
+var indexNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+
+// Problem is an issue found in a schema by ValidateSchema.
+type Problem struct {
+	// Path is the field of the schema at fault, e.g.
+	// GlobalSecondaryIndexes[0].Projection.
+	Path string
+	// Message describes what is wrong.
+	Message string
+	// Fix suggests how to correct it.
+	Fix string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s (fix: %s)", p.Path, p.Message, p.Fix)
+}
+
+// ValidateSchema checks a schema for the mistakes DynamoDB rejects with terse
+// ValidationException messages: keys missing from the attribute definitions,
+// unused definitions, malformed key schemas and indexes without projection,
+// among others. It needs no DynamoDB instance, so schemas can be checked in
+// plain unit tests. CreateTestingTable runs it before creating the table.
+//
+// The table name and capacity are not checked, as CreateTestingTable sets
+// them itself.
+func ValidateSchema(schema dynamodb.CreateTableInput) []Problem {
+	v := schemaValidator{definitions: map[string]types.ScalarAttributeType{}, used: map[string]bool{}}
+	v.attributeDefinitions(schema.AttributeDefinitions)
+
+	tableHash, tableRange := v.keySchema("KeySchema", schema.KeySchema)
+
+	indexNames := map[string]string{}
+	projected := 0
+	checkIndex := func(path, name string, projection *types.Projection) {
+		switch {
+		case name == "":
+			v.add(path+".IndexName", "index has no name", "set IndexName")
+		case !indexNamePattern.MatchString(name):
+			v.add(path+".IndexName", fmt.Sprintf("index name '%s' is invalid", name),
+				"use 3 to 255 letters, digits, '_', '-' or '.'")
+		case indexNames[name] != "":
+			v.add(path+".IndexName", fmt.Sprintf("index name '%s' is already used by %s", name, indexNames[name]),
+				"give every index a unique name")
+		default:
+			indexNames[name] = path
+		}
+		projected += v.projection(path+".Projection", projection)
+	}
+
+	if len(schema.GlobalSecondaryIndexes) > maxGlobalSecondaryIndexes {
+		v.add("GlobalSecondaryIndexes", fmt.Sprintf("%d global secondary indexes, at most %d are allowed", len(schema.GlobalSecondaryIndexes), maxGlobalSecondaryIndexes),
+			"remove indexes or split the table")
+	}
+	for i, gsi := range schema.GlobalSecondaryIndexes {
+		path := fmt.Sprintf("GlobalSecondaryIndexes[%d]", i)
+		checkIndex(path, aws.ToString(gsi.IndexName), gsi.Projection)
+		v.keySchema(path+".KeySchema", gsi.KeySchema)
+	}
+
+	if len(schema.LocalSecondaryIndexes) > maxLocalSecondaryIndexes {
+		v.add("LocalSecondaryIndexes", fmt.Sprintf("%d local secondary indexes, at most %d are allowed", len(schema.LocalSecondaryIndexes), maxLocalSecondaryIndexes),
+			"remove indexes or use global secondary indexes instead")
+	}
+	if len(schema.LocalSecondaryIndexes) > 0 && tableHash != "" && tableRange == "" {
+		v.add("LocalSecondaryIndexes", "local secondary indexes need a table with a RANGE key",
+			"add a RANGE key to KeySchema or use global secondary indexes instead")
+	}
+	for i, lsi := range schema.LocalSecondaryIndexes {
+		path := fmt.Sprintf("LocalSecondaryIndexes[%d]", i)
+		checkIndex(path, aws.ToString(lsi.IndexName), lsi.Projection)
+		hash, rangeKey := v.keySchema(path+".KeySchema", lsi.KeySchema)
+		if hash != "" && tableHash != "" && hash != tableHash {
+			v.add(path+".KeySchema[0].AttributeName", fmt.Sprintf("HASH key '%s' differs from the table HASH key '%s'", hash, tableHash),
+				fmt.Sprintf("use '%s' as HASH key, or make it a global secondary index", tableHash))
+		}
+		if hash != "" && rangeKey == "" {
+			v.add(path+".KeySchema", "local secondary index has no RANGE key",
+				"add a RANGE key element, local secondary indexes only differ from the table by it")
+		}
+	}
+
+	if projected > maxProjectedAttributes {
+		v.add("NonKeyAttributes", fmt.Sprintf("indexes project %d non-key attributes, at most %d are allowed", projected, maxProjectedAttributes),
+			"project fewer attributes or use ProjectionType ALL")
+	}
+
+	for i, d := range schema.AttributeDefinitions {
+		name := aws.ToString(d.AttributeName)
+		if name != "" && !v.used[name] {
+			v.add(fmt.Sprintf("AttributeDefinitions[%d]", i), fmt.Sprintf("attribute '%s' is defined but not used by any key", name),
+				"remove it, only the key attributes of the table and its indexes are defined")
+		}
+	}
+
+	return v.problems
+}
+
+type schemaValidator struct {
+	definitions map[string]types.ScalarAttributeType
+	used        map[string]bool
+	problems    []Problem
+}
+
+func (v *schemaValidator) add(path, message, fix string) {
+	v.problems = append(v.problems, Problem{Path: path, Message: message, Fix: fix})
+}
+
+func (v *schemaValidator) attributeDefinitions(definitions []types.AttributeDefinition) {
+	for i, d := range definitions {
+		path := fmt.Sprintf("AttributeDefinitions[%d]", i)
+		name := aws.ToString(d.AttributeName)
+		if name == "" {
+			v.add(path+".AttributeName", "attribute has no name", "set AttributeName")
+			continue
+		}
+		if _, ok := v.definitions[name]; ok {
+			v.add(path, fmt.Sprintf("attribute '%s' is defined more than once", name), "remove the duplicate definition")
+			continue
+		}
+		switch d.AttributeType {
+		case types.ScalarAttributeTypeS, types.ScalarAttributeTypeN, types.ScalarAttributeTypeB:
+		default:
+			v.add(path+".AttributeType", fmt.Sprintf("attribute '%s' has type '%s'", name, d.AttributeType),
+				"use S, N or B, key attributes cannot have other types")
+		}
+		v.definitions[name] = d.AttributeType
+	}
+}
+
+// keySchema checks a key schema and returns its HASH and RANGE attributes.
+func (v *schemaValidator) keySchema(path string, keys []types.KeySchemaElement) (string, string) {
+	if len(keys) == 0 {
+		v.add(path, "key schema is empty", "add a HASH key element, and optionally a RANGE one")
+		return "", ""
+	}
+	if len(keys) > 2 {
+		v.add(path, fmt.Sprintf("key schema has %d elements", len(keys)), "keep one HASH and at most one RANGE key element")
+	}
+
+	var hash, rangeKey string
+	for i, k := range keys {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		name := aws.ToString(k.AttributeName)
+		if name == "" {
+			v.add(elemPath+".AttributeName", "key has no attribute name", "set AttributeName")
+			continue
+		}
+		v.used[name] = true
+		if _, ok := v.definitions[name]; !ok {
+			v.add(elemPath+".AttributeName", fmt.Sprintf("attribute '%s' is not in AttributeDefinitions", name),
+				fmt.Sprintf("add {AttributeName: \"%s\", AttributeType: S, N or B} to AttributeDefinitions", name))
+		}
+
+		switch {
+		case i == 0 && k.KeyType != types.KeyTypeHash:
+			v.add(elemPath+".KeyType", fmt.Sprintf("first key '%s' has type '%s'", name, k.KeyType), "the first key element must be HASH")
+		case i == 1 && k.KeyType != types.KeyTypeRange:
+			v.add(elemPath+".KeyType", fmt.Sprintf("second key '%s' has type '%s'", name, k.KeyType), "the second key element must be RANGE")
+		case i == 0:
+			hash = name
+		case i == 1 && name == hash:
+			v.add(elemPath+".AttributeName", fmt.Sprintf("attribute '%s' is both HASH and RANGE key", name), "use a different attribute as RANGE key")
+		case i == 1:
+			rangeKey = name
+		}
+	}
+	return hash, rangeKey
+}
+
+// projection checks an index projection and returns how many non-key
+// attributes it adds.
+func (v *schemaValidator) projection(path string, projection *types.Projection) int {
+	if projection == nil {
+		v.add(path, "index has no projection", "set Projection to &types.Projection{ProjectionType: types.ProjectionTypeAll}")
+		return 0
+	}
+
+	switch projection.ProjectionType {
+	case types.ProjectionTypeInclude:
+		if len(projection.NonKeyAttributes) == 0 {
+			v.add(path+".NonKeyAttributes", "INCLUDE projection lists no attributes",
+				"list the projected attributes in NonKeyAttributes, or use KEYS_ONLY")
+		}
+		return len(projection.NonKeyAttributes)
+	case types.ProjectionTypeAll, types.ProjectionTypeKeysOnly:
+		if len(projection.NonKeyAttributes) > 0 {
+			v.add(path+".NonKeyAttributes", fmt.Sprintf("NonKeyAttributes are only allowed with INCLUDE, not %s", projection.ProjectionType),
+				"remove NonKeyAttributes or use ProjectionType INCLUDE")
+		}
+	case "":
+		v.add(path+".ProjectionType", "projection has no type", "set ProjectionType to ALL, KEYS_ONLY or INCLUDE")
+	default:
+		v.add(path+".ProjectionType", fmt.Sprintf("unknown projection type '%s'", projection.ProjectionType),
+			"use ALL, KEYS_ONLY or INCLUDE")
+	}
+	return 0
+}
+
+// schemaError formats problems into an error wrapping ErrInvalidSchema.
+func schemaError(problems []Problem) error {
+	lines := make([]string, len(problems))
+	for i, p := range problems {
+		lines[i] = "\t" + p.String()
+	}
+	return fmt.Errorf("%w, %d problem(s):\n%s", ErrInvalidSchema, len(problems), strings.Join(lines, "\n"))
+}
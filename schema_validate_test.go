@@ -0,0 +1,205 @@
+package dynamotest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		schema dynamodb.CreateTableInput
+		want   []dynamotest.Problem
+	}{
+		"valid schema": {
+			schema: dynamodb.CreateTableInput{
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("createdAt"), AttributeType: types.ScalarAttributeTypeN},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+				},
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+					{
+						IndexName: aws.String("ByStatus"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+						},
+						Projection: &types.Projection{
+							ProjectionType:   types.ProjectionTypeInclude,
+							NonKeyAttributes: []string{"total"},
+						},
+					},
+				},
+				LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+					{
+						IndexName: aws.String("ByCreatedAt"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+							{AttributeName: aws.String("createdAt"), KeyType: types.KeyTypeRange},
+						},
+						Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+					},
+				},
+			},
+		},
+
+		"key not defined and unused definition": {
+			schema: dynamodb.CreateTableInput{
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+				},
+			},
+			want: []dynamotest.Problem{
+				{
+					Path:    "KeySchema[0].AttributeName",
+					Message: "attribute 'pk' is not in AttributeDefinitions",
+					Fix:     `add {AttributeName: "pk", AttributeType: S, N or B} to AttributeDefinitions`,
+				},
+				{
+					Path:    "AttributeDefinitions[0]",
+					Message: "attribute 'id' is defined but not used by any key",
+					Fix:     "remove it, only the key attributes of the table and its indexes are defined",
+				},
+			},
+		},
+
+		"malformed key schema": {
+			schema: dynamodb.CreateTableInput{
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: "BOOL"},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeRange},
+				},
+			},
+			want: []dynamotest.Problem{
+				{
+					Path:    "AttributeDefinitions[0].AttributeType",
+					Message: "attribute 'pk' has type 'BOOL'",
+					Fix:     "use S, N or B, key attributes cannot have other types",
+				},
+				{
+					Path:    "KeySchema[0].KeyType",
+					Message: "first key 'pk' has type 'RANGE'",
+					Fix:     "the first key element must be HASH",
+				},
+			},
+		},
+
+		"index problems": {
+			schema: dynamodb.CreateTableInput{
+				AttributeDefinitions: []types.AttributeDefinition{
+					{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+					{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+				},
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+				},
+				GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+					{
+						IndexName: aws.String("ByStatus"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+						},
+					},
+					{
+						IndexName: aws.String("ByStatus"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+						},
+						Projection: &types.Projection{ProjectionType: types.ProjectionTypeInclude},
+					},
+				},
+				LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+					{
+						IndexName: aws.String("ByStatusLocal"),
+						KeySchema: []types.KeySchemaElement{
+							{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash},
+						},
+						Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+					},
+				},
+			},
+			want: []dynamotest.Problem{
+				{
+					Path:    "GlobalSecondaryIndexes[0].Projection",
+					Message: "index has no projection",
+					Fix:     "set Projection to &types.Projection{ProjectionType: types.ProjectionTypeAll}",
+				},
+				{
+					Path:    "GlobalSecondaryIndexes[1].IndexName",
+					Message: "index name 'ByStatus' is already used by GlobalSecondaryIndexes[0]",
+					Fix:     "give every index a unique name",
+				},
+				{
+					Path:    "GlobalSecondaryIndexes[1].Projection.NonKeyAttributes",
+					Message: "INCLUDE projection lists no attributes",
+					Fix:     "list the projected attributes in NonKeyAttributes, or use KEYS_ONLY",
+				},
+				{
+					Path:    "LocalSecondaryIndexes",
+					Message: "local secondary indexes need a table with a RANGE key",
+					Fix:     "add a RANGE key to KeySchema or use global secondary indexes instead",
+				},
+				{
+					Path:    "LocalSecondaryIndexes[0].KeySchema[0].AttributeName",
+					Message: "HASH key 'status' differs from the table HASH key 'pk'",
+					Fix:     "use 'pk' as HASH key, or make it a global secondary index",
+				},
+				{
+					Path:    "LocalSecondaryIndexes[0].KeySchema",
+					Message: "local secondary index has no RANGE key",
+					Fix:     "add a RANGE key element, local secondary indexes only differ from the table by it",
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := dynamotest.ValidateSchema(tc.schema)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("problems didn't match (-want / +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCreateTestingTableE_InvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	// The schema is validated before any call to DynamoDB, so no instance is needed.
+	var client dynamotest.Client
+	_, err := client.CreateTestingTableE(context.Background(), "test", dynamodb.CreateTableInput{
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+		},
+	})
+	if !errors.Is(err, dynamotest.ErrInvalidSchema) {
+		t.Fatalf("expected ErrInvalidSchema, got %v", err)
+	}
+	want := "KeySchema[0].AttributeName: attribute 'pk' is not in AttributeDefinitions"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error to contain '%s', got '%v'", want, err)
+	}
+}
@@ -0,0 +1,132 @@
+package dynamotest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// marshalItems marshals initial data into items.
+func marshalItems(initialData []any) ([]map[string]types.AttributeValue, error) {
+	items := make([]map[string]types.AttributeValue, 0, len(initialData))
+	for i, itemData := range initialData {
+		item, err := toItem(itemData)
+		if err != nil {
+			return nil, fmt.Errorf("%w: item %d: %w", ErrSeedMarshal, i, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// toItem marshals a Go value into an item. Items already in their DynamoDB
+// form, such as fixtures, are kept as is.
+func toItem(v any) (map[string]types.AttributeValue, error) {
+	if item, ok := v.(map[string]types.AttributeValue); ok {
+		return item, nil
+	}
+	return attributevalue.MarshalMap(v)
+}
+
+// writeItems writes the items to the table in chunks accepted by
+// BatchWriteItem, resending unprocessed items with backoff. Chunks are written
+// by up to Client.SeedConcurrency goroutines.
+func (c Client) writeItems(ctx context.Context, table string, items []map[string]types.AttributeValue) error {
+	requests := make([]types.WriteRequest, 0, len(items))
+	for _, item := range items {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+
+	if unwritten, err := c.batchWrite(ctx, table, requests); unwritten > 0 {
+		return fmt.Errorf("%w to table '%s': %d of %d items could not be written: %w",
+			ErrSeedWrite, table, unwritten, len(items), err)
+	}
+	return nil
+}
+
+// deleteItems deletes the items with the given keys like writeItems writes them.
+func (c Client) deleteItems(ctx context.Context, table string, keys []map[string]types.AttributeValue) error {
+	requests := make([]types.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+	}
+
+	if unwritten, err := c.batchWrite(ctx, table, requests); unwritten > 0 {
+		return fmt.Errorf("%w '%s': %d of %d items could not be deleted: %w",
+			ErrTableReset, table, unwritten, len(keys), err)
+	}
+	return nil
+}
+
+// batchWrite sends the requests in chunks and returns how many of them could
+// not be processed, together with the first error met.
+func (c Client) batchWrite(ctx context.Context, table string, requests []types.WriteRequest) (int, error) {
+	workers := max(c.SeedConcurrency, 1)
+	var (
+		mu        sync.Mutex
+		unwritten int
+		firstErr  error
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, workers)
+	)
+	for start := 0; start < len(requests); start += batchWriteLimit {
+		chunk := requests[start:min(start+batchWriteLimit, len(requests))]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(chunk []types.WriteRequest) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			n, err := c.writeChunk(ctx, table, chunk)
+			mu.Lock()
+			defer mu.Unlock()
+			unwritten += n
+			if firstErr == nil {
+				firstErr = err
+			}
+		}(chunk)
+	}
+	wg.Wait()
+
+	if unwritten > 0 && firstErr == nil {
+		firstErr = fmt.Errorf("items still unprocessed after %d attempts", batchWriteAttempts)
+	}
+	return unwritten, firstErr
+}
+
+// writeChunk writes a single chunk and returns the number of items that could
+// not be written.
+func (c Client) writeChunk(ctx context.Context, table string, chunk []types.WriteRequest) (int, error) {
+	backoff := batchWriteBackoff
+	for attempt := 1; ; attempt++ {
+		out, err := c.Client.BatchWriteItem(internal(ctx), &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{
+				table: chunk,
+			},
+		})
+		if err != nil {
+			return len(chunk), err
+		}
+
+		chunk = out.UnprocessedItems[table]
+		if len(chunk) == 0 {
+			return 0, nil
+		}
+		if attempt == batchWriteAttempts {
+			return len(chunk), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return len(chunk), ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, batchWriteMaxBackoff)
+	}
+}
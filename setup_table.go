@@ -5,10 +5,12 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
@@ -22,61 +24,224 @@ import (
 ** schema is the schema of the table except the table name which is generated by the function using the tablePrefix and the billing mode is set to PayPerRequest
 **
 ** initialData is a type alias for any type of data that can be used to populate the table
+**
+** The table and all its global secondary indexes are ACTIVE by the time the function returns, waiting at most
+** Client.TableActiveTimeout
+**
+** The table is deleted once the test and its subtests complete, unless the test failed, so it can still be
+** inspected. This is controlled by Client.KeepTables and can be overridden with the DYNAMOTEST_KEEP_TABLES
+** environment variable
 **/
 func (c Client) CreateTestingTable(t *testing.T, tablePrefix string, schema dynamodb.CreateTableInput, initialData ...any) string {
 	t.Helper()
+
+	table, err := c.CreateTestingTableE(context.Background(), tablePrefix, schema, initialData...)
+	if table != "" {
+		// The table may exist even though it could not be waited for or
+		// seeded, in which case it is cleaned up all the same.
+		t.Cleanup(func() {
+			if !c.keepTables().deleteTable(t.Failed()) {
+				t.Logf("Table '%s' has been kept", table)
+				return
+			}
+			if err := c.deleteTable(context.Background(), table); err != nil {
+				// Not a test failure: the instance may already be gone when the
+				// caller purges it before the cleanup runs.
+				t.Logf("Could not delete table '%s': %v", table, err)
+				return
+			}
+			t.Logf("Table '%s' has been deleted", table)
+		})
+	}
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Logf("Table '%s' has been created", table)
+
+	return table
+}
+
+// CreateTestingTableFromDefinition works like CreateTestingTable for a table
+// loaded from infrastructure code, such as with TableFromTerraform, enabling
+// its time to live once the table is created.
+func (c Client) CreateTestingTableFromDefinition(t *testing.T, tablePrefix string, definition TableDefinition, initialData ...any) string {
+	t.Helper()
+
+	table := c.CreateTestingTable(t, tablePrefix, definition.Schema, initialData...)
+
+	// Disabling a time to live which was never enabled is rejected.
+	if ttl := definition.TimeToLive; ttl != nil && aws.ToBool(ttl.Enabled) {
+		_, err := c.Client.UpdateTimeToLive(internal(context.Background()), &dynamodb.UpdateTimeToLiveInput{
+			TableName:               aws.String(table),
+			TimeToLiveSpecification: ttl,
+		})
+		if err != nil {
+			t.Fatalf("could not enable time to live on table '%s': %v", table, err)
+		}
+	}
+
+	return table
+}
+
+// KeepTables decides whether tables created by CreateTestingTable are kept
+// after the test completes.
+type KeepTables int
+
+const (
+	// KeepTablesOnFailure deletes tables of passing tests and keeps the ones of
+	// failing tests for investigation. This is the default.
+	KeepTablesOnFailure KeepTables = iota
+	// KeepTablesNever always deletes tables.
+	KeepTablesNever
+	// KeepTablesAlways never deletes tables.
+	KeepTablesAlways
+)
+
+func (k KeepTables) deleteTable(failed bool) bool {
+	switch k {
+	case KeepTablesNever:
+		return true
+	case KeepTablesAlways:
+		return false
+	default:
+		return !failed
+	}
+}
+
+// keepTables returns the table policy, giving the environment precedence over
+// the client so tables can be kept while debugging without changing code.
+func (c Client) keepTables() KeepTables {
+	switch strings.ToLower(os.Getenv(keepTablesEnv)) {
+	case "1", "true", "always":
+		return KeepTablesAlways
+	case "0", "false", "never":
+		return KeepTablesNever
+	case "on-failure":
+		return KeepTablesOnFailure
+	}
+	return c.KeepTables
+}
+
+// waitTableActive polls the table until it and all its global secondary
+// indexes report ACTIVE, or Client.TableActiveTimeout is exceeded.
+func (c Client) waitTableActive(ctx context.Context, table string) error {
+	timeout := c.TableActiveTimeout
+	if timeout <= 0 {
+		timeout = tableActiveTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lastStatus := "unknown"
+	for {
+		out, err := c.Client.DescribeTable(internal(ctx), &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		if err == nil {
+			var active bool
+			active, lastStatus = tableStatus(out.Table)
+			if active {
+				return nil
+			}
+		} else if ctx.Err() == nil {
+			lastStatus = err.Error()
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: table '%s' after %s, last observed %s", ErrTableNotActive, table, timeout, lastStatus)
+		case <-time.After(tableActivePollInterval):
+		}
+	}
+}
+
+// tableStatus reports whether the table and its global secondary indexes are
+// active, together with a description of their statuses.
+func tableStatus(table *types.TableDescription) (bool, string) {
+	active := table.TableStatus == types.TableStatusActive
+	status := fmt.Sprintf("table status %s", table.TableStatus)
+	for _, gsi := range table.GlobalSecondaryIndexes {
+		if gsi.IndexStatus != types.IndexStatusActive {
+			active = false
+		}
+		status += fmt.Sprintf(", index '%s' status %s", aws.ToString(gsi.IndexName), gsi.IndexStatus)
+	}
+	return active, status
+}
+
+// deleteTable deletes the table and waits until DynamoDB no longer reports it.
+func (c Client) deleteTable(ctx context.Context, table string) error {
+	_, err := c.Client.DeleteTable(internal(ctx), &dynamodb.DeleteTableInput{TableName: aws.String(table)})
+	if err != nil {
+		return err
+	}
+	// The default delays of the waiter, tens of seconds, are meant for
+	// DynamoDB, whereas DynamoDB Local deletes tables right away.
+	waiter := dynamodb.NewTableNotExistsWaiter(c.Client, func(o *dynamodb.TableNotExistsWaiterOptions) {
+		o.MinDelay = tableActivePollInterval
+		o.MaxDelay = tableActivePollInterval
+	})
+	return waiter.Wait(internal(ctx), &dynamodb.DescribeTableInput{TableName: aws.String(table)}, tableDeleteTimeout)
+}
+
+// CreateTestingTableE works like CreateTestingTable, but returns an error
+// instead of failing the test. The error wraps one of ErrTableName,
+// ErrInvalidSchema, ErrTableCreate, ErrTableNotActive, ErrSeedMarshal or
+// ErrSeedWrite.
+func (c Client) CreateTestingTableE(ctx context.Context, tablePrefix string, schema dynamodb.CreateTableInput, initialData ...any) (string, error) {
 	randomBytes := make([]byte, 8)
 	_, err := rand.Read(randomBytes)
 	if err != nil {
-		t.Fatalf("Could not generate random suffix for  table name: %v", err)
+		return "", fmt.Errorf("%w: %w", ErrTableName, err)
 	}
 	//randomBytes to suffix
 	suffix := binary.BigEndian.Uint32(randomBytes)
 	// Generate a random table name
 	table := fmt.Sprintf("%s-%d", tablePrefix, suffix)
 
-	putItems := make([]*types.PutRequest, 0, len(initialData))
-	for _, itemData := range initialData {
-		item, err := attributevalue.MarshalMap(itemData)
-		if err != nil {
-			panic("failed to marshall initial data" + err.Error())
-		}
+	items, err := marshalItems(initialData)
+	if err != nil {
+		return "", err
+	}
 
-		putItems = append(putItems, &types.PutRequest{
-			Item: item,
-		})
+	if problems := ValidateSchema(schema); len(problems) > 0 {
+		return "", schemaError(problems)
 	}
 
 	// Set the table name to the generated table name
 	schema.TableName = aws.String(table)
 	schema.BillingMode = types.BillingModePayPerRequest
+	// Capacity cannot be given with PayPerRequest, but schemas loaded from
+	// production definitions often have it.
+	schema.ProvisionedThroughput = nil
+	if len(schema.GlobalSecondaryIndexes) > 0 {
+		indexes := make([]types.GlobalSecondaryIndex, len(schema.GlobalSecondaryIndexes))
+		copy(indexes, schema.GlobalSecondaryIndexes)
+		for i := range indexes {
+			indexes[i].ProvisionedThroughput = nil
+		}
+		schema.GlobalSecondaryIndexes = indexes
+	}
 
 	// Add extra retry setup in case Docker instance is busy. This can happen
 	// especially within a CI environment, and the default retry count of 3
 	// times is too fragile.
 	opt := func(o *dynamodb.Options) { o.RetryMaxAttempts = 10 }
 
-	_, err = c.Client.CreateTable(context.Background(), &schema, opt)
+	_, err = c.Client.CreateTable(internal(ctx), &schema, opt)
 	if err != nil {
-		t.Fatalf("Could not create table '%s': %v", table, err)
+		return "", fmt.Errorf("%w '%s': %w", ErrTableCreate, table, err)
 	}
+	c.leased.add(table)
 
-	if len(putItems) > 0 {
-		puts := make([]types.WriteRequest, 0, len(putItems))
-		for _, d := range putItems {
-			puts = append(puts, types.WriteRequest{PutRequest: d})
-		}
-		_, err = c.Client.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{
-			RequestItems: map[string][]types.WriteRequest{
-				table: puts,
-			},
-		})
-		if err != nil {
-			t.Fatalf("Could not write data to table '%s': %v", table, err)
-		}
+	if err := c.waitTableActive(ctx, table); err != nil {
+		return table, err
 	}
 
-	t.Logf("Table '%s' has been created", table)
+	if len(items) > 0 {
+		if err := c.writeItems(ctx, table, items); err != nil {
+			return table, err
+		}
+	}
 
-	return table
+	return table, nil
 }
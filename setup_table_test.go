@@ -2,6 +2,8 @@ package dynamotest_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -11,24 +13,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/rozen03/dynamotest"
+	"github.com/rozen03/dynamotest/avcmp"
 )
 
-func compareAttributeValues(expected, actual types.AttributeValue) bool {
-	switch e := expected.(type) {
-	case *types.AttributeValueMemberS:
-		a, ok := actual.(*types.AttributeValueMemberS)
-		return ok && e.Value == a.Value
-	case *types.AttributeValueMemberN:
-		a, ok := actual.(*types.AttributeValueMemberN)
-		return ok && e.Value == a.Value
-	case *types.AttributeValueMemberBOOL:
-		a, ok := actual.(*types.AttributeValueMemberBOOL)
-		return ok && e.Value == a.Value
-	default:
-		return false
-	}
-}
-
 type testData struct {
 	PK string `dynamodbav:"test_PK" json:"test_PK"`
 
@@ -377,8 +364,8 @@ func Test_Query(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			client, clean := dynamotest.NewDynamoDB()
-			defer clean()
+			// Purged after the tables created below have been cleaned up.
+			client := dynamotest.New(t)
 
 			tableName := client.CreateTestingTable(t, "test", tc.schema, tc.initialData...)
 			tc.query.TableName = aws.String(tableName)
@@ -404,7 +391,7 @@ func Test_Query(t *testing.T) {
 						t.Errorf("missing key %s in result", key)
 						continue
 					}
-					if !compareAttributeValues(expectedValue, actualValue) {
+					if !avcmp.Equal(expectedValue, actualValue) {
 						t.Errorf("value mismatch for key %s: expected %v, got %v", key, expectedValue, actualValue)
 					}
 				}
@@ -468,8 +455,8 @@ func TestQueryWithUnmarshal(t *testing.T) {
 			// dynamotest can be safely used in parallel testing.
 			t.Parallel()
 
-			client, clean := dynamotest.NewDynamoDB()
-			defer clean()
+			// Purged after the tables created below have been cleaned up.
+			client := dynamotest.New(t)
 
 			// Data prep, use simple context.
 			tableName := client.CreateTestingTable(t, "test", tc.schema, tc.initialData...)
@@ -497,3 +484,156 @@ func TestQueryWithUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+type unmarshallableData struct{}
+
+func (unmarshallableData) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return nil, errors.New("cannot be marshalled")
+}
+
+func TestCreateTestingTableE_SeedMarshalError(t *testing.T) {
+	t.Parallel()
+
+	// Marshalling happens before any call to DynamoDB, so no instance is needed.
+	var client dynamotest.Client
+	_, err := client.CreateTestingTableE(context.Background(), "test", dynamodb.CreateTableInput{}, map[string]any{
+		"id": unmarshallableData{},
+	})
+	if !errors.Is(err, dynamotest.ErrSeedMarshal) {
+		t.Fatalf("expected ErrSeedMarshal, got %v", err)
+	}
+}
+
+func TestCreateTestingTable_DeletesTableOnCleanup(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	var table string
+	t.Run("create", func(t *testing.T) {
+		table = client.CreateTestingTable(t, "cleanup", dynamodb.CreateTableInput{
+			AttributeDefinitions: []types.AttributeDefinition{
+				{
+					AttributeName: aws.String("id"),
+					AttributeType: types.ScalarAttributeTypeS,
+				},
+			},
+			KeySchema: []types.KeySchemaElement{
+				{
+					AttributeName: aws.String("id"),
+					KeyType:       types.KeyTypeHash,
+				},
+			},
+		})
+	})
+
+	out, err := client.ListTables(context.Background(), &dynamodb.ListTablesInput{})
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+	for _, name := range out.TableNames {
+		if name == table {
+			t.Errorf("expected table '%s' to be deleted", table)
+		}
+	}
+}
+
+func TestCreateTestingTable_SeedsMoreThanOneBatch(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+	client.SeedConcurrency = 4
+
+	const count = 1000
+	initialData := make([]any, 0, count)
+	for i := 0; i < count; i++ {
+		initialData = append(initialData, map[string]interface{}{
+			"id":    fmt.Sprintf("item-%d", i),
+			"index": i,
+		})
+	}
+
+	table := client.CreateTestingTable(t, "seed", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	}, initialData...)
+
+	var got int32
+	paginator := dynamodb.NewScanPaginator(client.Client, &dynamodb.ScanInput{
+		TableName: aws.String(table),
+		Select:    types.SelectCount,
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(context.Background())
+		if err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+		got += out.Count
+	}
+
+	if got != count {
+		t.Errorf("expected %d items, got %d", count, got)
+	}
+}
+
+func TestCreateTestingTable_WaitsForActiveStatus(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t, dynamotest.WithFlags("-inMemory", "-delayTransientStatuses"))
+
+	table := client.CreateTestingTable(t, "active", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("name"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String("name-index"),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("name"),
+						KeyType:       types.KeyTypeHash,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeAll,
+				},
+			},
+		},
+	})
+
+	out, err := client.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		t.Fatalf("failed to describe table: %v", err)
+	}
+	if out.Table.TableStatus != types.TableStatusActive {
+		t.Errorf("expected table to be ACTIVE, got %s", out.Table.TableStatus)
+	}
+	for _, gsi := range out.Table.GlobalSecondaryIndexes {
+		if gsi.IndexStatus != types.IndexStatusActive {
+			t.Errorf("expected index '%s' to be ACTIVE, got %s", aws.ToString(gsi.IndexName), gsi.IndexStatus)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package dynamotest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/rozen03/dynamotest/avcmp"
+)
+
+var updateSnapshots = flag.Bool("dynamotest.update", false, "rewrite the golden files of dynamotest.MatchSnapshot")
+
+// SnapshotOption configures MatchSnapshot.
+type SnapshotOption func(*snapshotOptions)
+
+type snapshotOptions struct {
+	ignore []string
+}
+
+// IgnoreAttributes leaves volatile attributes such as timestamps or generated
+// IDs out of the snapshot. Paths name attributes like "createdAt", nested map
+// attributes like "meta.requestId", and list elements like "events[0]" or
+// "events[*].at" for every element.
+func IgnoreAttributes(paths ...string) SnapshotOption {
+	return func(o *snapshotOptions) { o.ignore = append(o.ignore, paths...) }
+}
+
+// MatchSnapshot compares every item of the table with the golden file
+// testdata/<TestName>.dynamo.json, failing the test with a diff when they
+// differ. Running the tests with -dynamotest.update, or with the
+// DYNAMOTEST_UPDATE environment variable set, writes the golden file instead,
+// which is also how it is created the first time.
+//
+// Items are written as DynamoDB JSON sorted by primary key, see
+// WriteTypedJSON, so golden files are diffable and can be committed.
+func MatchSnapshot(t *testing.T, c Client, table string, opts ...SnapshotOption) {
+	t.Helper()
+
+	o := &snapshotOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	items, err := c.DumpTableE(context.Background(), table)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for _, path := range o.ignore {
+		segments, err := parseAttributePath(path)
+		if err != nil {
+			t.Fatalf("invalid attribute path '%s': %v", path, err)
+		}
+		for _, item := range items {
+			removeAttribute(item, segments)
+		}
+	}
+
+	var got bytes.Buffer
+	if err := WriteTypedJSON(&got, items); err != nil {
+		t.Fatalf("could not write snapshot of table '%s': %v", table, err)
+	}
+
+	golden := filepath.Join(snapshotDir, filepath.FromSlash(t.Name())+snapshotExt)
+	if shouldUpdateSnapshots() {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatalf("could not create snapshot directory: %v", err)
+		}
+		if err := os.WriteFile(golden, got.Bytes(), 0o644); err != nil {
+			t.Fatalf("could not write snapshot: %v", err)
+		}
+		t.Logf("Snapshot '%s' has been updated", golden)
+		return
+	}
+
+	want, err := LoadFixtures(golden)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("snapshot '%s' does not exist, run the test with -dynamotest.update to create it", golden)
+	}
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if diff := avcmp.Diff(want, items); diff != "" {
+		t.Errorf("table '%s' didn't match snapshot '%s' (-want / +got)\n%s\nrun the test with -dynamotest.update to accept the changes",
+			table, golden, diff)
+	}
+}
+
+func shouldUpdateSnapshots() bool {
+	if *updateSnapshots {
+		return true
+	}
+	switch strings.ToLower(os.Getenv(updateSnapshotsEnv)) {
+	case "1", "true":
+		return true
+	}
+	return false
+}
+
+// attributeSegment is a step of an attribute path: a map attribute name, or
+// a list index, -1 standing for every element.
+type attributeSegment struct {
+	name  string
+	index int
+	list  bool
+}
+
+func parseAttributePath(path string) ([]attributeSegment, error) {
+	var segments []attributeSegment
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" && (len(segments) == 0 || rest == "") {
+			return nil, errors.New("empty attribute name")
+		}
+		if name != "" {
+			segments = append(segments, attributeSegment{name: name})
+		}
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, errors.New("unterminated list index")
+			}
+			segment := attributeSegment{list: true, index: -1}
+			if index != "*" {
+				i, err := strconv.Atoi(index)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid list index '%s'", index)
+				}
+				segment.index = i
+			}
+			segments = append(segments, segment)
+			if after == "" {
+				break
+			}
+			if !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("unexpected '%s' after list index", after)
+			}
+			rest = after[1:]
+		}
+	}
+	return segments, nil
+}
+
+// removeAttribute removes the attribute at the path from the item, if any.
+func removeAttribute(item map[string]types.AttributeValue, segments []attributeSegment) {
+	first := segments[0]
+	if len(segments) == 1 {
+		delete(item, first.name)
+		return
+	}
+	if av, ok := item[first.name]; ok {
+		item[first.name] = removeNested(av, segments[1:])
+	}
+}
+
+func removeNested(av types.AttributeValue, segments []attributeSegment) types.AttributeValue {
+	segment := segments[0]
+	switch v := av.(type) {
+	case *types.AttributeValueMemberM:
+		if !segment.list {
+			removeAttribute(v.Value, segments)
+		}
+	case *types.AttributeValueMemberL:
+		if !segment.list {
+			return av
+		}
+		list := make([]types.AttributeValue, 0, len(v.Value))
+		for i, e := range v.Value {
+			if segment.index >= 0 && segment.index != i {
+				list = append(list, e)
+				continue
+			}
+			if len(segments) > 1 {
+				list = append(list, removeNested(e, segments[1:]))
+			}
+		}
+		v.Value = list
+	}
+	return av
+}
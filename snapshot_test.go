@@ -0,0 +1,51 @@
+package dynamotest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/rozen03/dynamotest"
+)
+
+func TestMatchSnapshot(t *testing.T) {
+	t.Parallel()
+
+	client := dynamotest.New(t)
+
+	now := time.Now().Format(time.RFC3339Nano)
+	table := client.CreateTestingTable(t, "snapshot", dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	},
+		map[string]any{
+			"id":        "order-2",
+			"status":    "SHIPPED",
+			"createdAt": now,
+			"meta":      map[string]any{"requestId": now, "source": "web"},
+			"events":    []any{map[string]any{"type": "created", "at": now}},
+		},
+		map[string]any{
+			"id":        "order-1",
+			"status":    "PENDING",
+			"createdAt": now,
+			"tags":      []string{"gift"},
+		},
+	)
+
+	dynamotest.MatchSnapshot(t, client, table,
+		dynamotest.IgnoreAttributes("createdAt", "meta.requestId", "events[*].at"))
+}
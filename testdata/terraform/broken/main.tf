@@ -0,0 +1,14 @@
+variable "key" {
+  type = string
+}
+
+resource "aws_dynamodb_table" "sessions" {
+  name         = "sessions"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = var.key
+
+  attribute {
+    name = "id"
+    type = "S"
+  }
+}
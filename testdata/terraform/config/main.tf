@@ -0,0 +1,68 @@
+variable "environment" {
+  type = string
+}
+
+resource "aws_dynamodb_table" "orders" {
+  name           = "orders-${var.environment}"
+  billing_mode   = "PROVISIONED"
+  read_capacity  = 5
+  write_capacity = 5
+  hash_key       = "pk"
+  range_key      = "sk"
+
+  stream_enabled   = true
+  stream_view_type = "NEW_IMAGE"
+
+  attribute {
+    name = "pk"
+    type = "S"
+  }
+
+  attribute {
+    name = "sk"
+    type = "S"
+  }
+
+  attribute {
+    name = "status"
+    type = "S"
+  }
+
+  attribute {
+    name = "createdAt"
+    type = "N"
+  }
+
+  global_secondary_index {
+    name               = "ByStatus"
+    hash_key           = "status"
+    range_key          = "createdAt"
+    projection_type    = "INCLUDE"
+    non_key_attributes = ["total"]
+    read_capacity      = 1
+    write_capacity     = 1
+  }
+
+  local_secondary_index {
+    name            = "ByCreatedAt"
+    range_key       = "createdAt"
+    projection_type = "KEYS_ONLY"
+  }
+
+  ttl {
+    attribute_name = "expiresAt"
+    enabled        = true
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Environment = var.environment
+  }
+}
+
+resource "aws_sqs_queue" "events" {
+  name = "events"
+}
@@ -62,3 +62,10 @@ func TestRepositoryExample_Update(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "updated-value", updatedModel.Value)
 }
+
+func TestExampleModel_Schema(t *testing.T) {
+	schema, err := dynamotest.SchemaFromStruct[ExampleModel]()
+	assert.NoError(t, err)
+	assert.Equal(t, getSchema().KeySchema, schema.KeySchema)
+	assert.Equal(t, getSchema().AttributeDefinitions, schema.AttributeDefinitions)
+}
@@ -28,8 +28,8 @@ type DBClient interface {
 	) (*dynamodb.UpdateItemOutput, error)
 }
 type ExampleModel struct {
-	ID    string `dynamodbav:"pk"`
-	SK    string `dynamodbav:"sk"`
+	ID    string `dynamodbav:"pk" dynamotest:"hash"`
+	SK    string `dynamodbav:"sk" dynamotest:"range"`
 	Value string `dynamodbav:"value"`
 }
 type RepositoryExample struct {